@@ -2,6 +2,7 @@ package domain
 
 import (
 	"io"
+	"math"
 	"mime/multipart"
 	"time"
 )
@@ -20,6 +21,99 @@ const (
 	AssignmentHardLevel   AssignmentLevel = "HARD"
 )
 
+// DefaultAssignmentLevels are available in every workspace, a workspace level overrides their score
+var DefaultAssignmentLevels = []AssignmentLevel{
+	AssignmentEasyLevel,
+	AssignmentMediumLevel,
+	AssignmentHardLevel,
+}
+
+func GetDefaultLevelScore(level AssignmentLevel) (float64, bool) {
+	score, ok := assignmentScoreMap[level]
+	return score, ok
+}
+
+type GradingPriority string
+
+const (
+	GradingPriorityLow    GradingPriority = "LOW"
+	GradingPriorityNormal GradingPriority = "NORMAL"
+	GradingPriorityHigh   GradingPriority = "HIGH"
+)
+
+// GradingPriorityMap maps a grading priority onto the RabbitMQ message priority
+var GradingPriorityMap = map[GradingPriority]uint8{
+	GradingPriorityLow:    1,
+	GradingPriorityNormal: 5,
+	GradingPriorityHigh:   9,
+}
+
+type LatePolicy string
+
+const (
+	LatePolicyNone   LatePolicy = "NONE"
+	LatePolicyCutoff LatePolicy = "CUTOFF"
+	LatePolicyLinear LatePolicy = "LINEAR"
+	LatePolicyStep   LatePolicy = "STEP"
+)
+
+type LatePenaltyInterval string
+
+const (
+	LatePenaltyIntervalHour LatePenaltyInterval = "HOUR"
+	LatePenaltyIntervalDay  LatePenaltyInterval = "DAY"
+)
+
+type CheckerType string
+
+const (
+	CheckerTypeExact     CheckerType = "EXACT"
+	CheckerTypeToken     CheckerType = "TOKEN"
+	CheckerTypeFloat     CheckerType = "FLOAT"
+	CheckerTypeUnordered CheckerType = "UNORDERED"
+
+	// Checker program uploaded by the instructor
+	CheckerTypeCustom CheckerType = "CUSTOM"
+)
+
+type TemplateType string
+
+const (
+	TemplateTypeStarter TemplateType = "STARTER"
+
+	// Reference solution, never visible to members
+	TemplateTypeSolution TemplateType = "SOLUTION"
+)
+
+type TestcaseVisibility string
+
+const (
+	// Files and results are visible to members
+	TestcaseVisibilitySample TestcaseVisibility = "SAMPLE"
+
+	// Only results are visible to members
+	TestcaseVisibilityHidden TestcaseVisibility = "HIDDEN"
+
+	// Nothing is visible to members until the due date, then it is hidden
+	TestcaseVisibilitySecret TestcaseVisibility = "SECRET"
+)
+
+type GenerationStatus string
+
+const (
+	GenerationStatusGenerating GenerationStatus = "GENERATING"
+	GenerationStatusCompleted  GenerationStatus = "COMPLETED"
+	GenerationStatusFailed     GenerationStatus = "FAILED"
+)
+
+type SimilarityStatus string
+
+const (
+	SimilarityStatusRunning   SimilarityStatus = "RUNNING"
+	SimilarityStatusCompleted SimilarityStatus = "COMPLETED"
+	SimilarityStatusFailed    SimilarityStatus = "FAILED"
+)
+
 type AssignmentStatus string
 
 const (
@@ -27,54 +121,257 @@ const (
 	AssignmentStatusGrading     AssignmentStatus = "GRADING"
 	AssignmentStatusIncompleted AssignmentStatus = "INCOMPLETED"
 	AssignmentStatusComplete    AssignmentStatus = "COMPLETED"
+
+	// Submission only status, the grading result never arrives after all attempts
+	AssignmentStatusSystemFailure AssignmentStatus = "SYSTEM_FAILURE"
 )
 
 type Assignment struct {
-	Id                int             `json:"id" db:"id"`
-	WorkspaceId       int             `json:"-" db:"workspace_id"`
-	Name              string          `json:"name" db:"name"`
-	Description       string          `json:"description" db:"description"`
-	DetailUrl         string          `json:"detailUrl" db:"detail_url"`
-	MemoryLimit       int             `json:"memoryLimit" db:"memory_limit"`
-	TimeLimit         int             `json:"timeLimit" db:"time_limit"`
-	Level             AssignmentLevel `json:"level" db:"level"`
-	CreatedAt         time.Time       `json:"createdAt" db:"created_at"`
-	UpdatedAt         time.Time       `json:"updatedAt" db:"updated_at"`
-	PublishDate       time.Time       `json:"publishDate" db:"publish_date"`
-	IsAutoTrimEnabled bool            `json:"isAutoTrimEnabled" db:"is_auto_trim_enabled"`
-	DueDate           *time.Time      `json:"dueDate" db:"due_date"`
-	IsDeleted         bool            `json:"-" db:"is_deleted"`
+	Id                  int                 `json:"id" db:"id"`
+	WorkspaceId         int                 `json:"-" db:"workspace_id"`
+	Name                string              `json:"name" db:"name"`
+	Description         string              `json:"description" db:"description"`
+	DetailUrl           string              `json:"detailUrl" db:"detail_url"`
+	MemoryLimit         int                 `json:"memoryLimit" db:"memory_limit"`
+	TimeLimit           int                 `json:"timeLimit" db:"time_limit"`
+	Level               AssignmentLevel     `json:"level" db:"level"`
+	CustomMaxScore      *float64            `json:"customMaxScore" db:"max_score"`
+	LevelScore          *float64            `json:"-" db:"level_score"`
+	CreatedAt           time.Time           `json:"createdAt" db:"created_at"`
+	UpdatedAt           time.Time           `json:"updatedAt" db:"updated_at"`
+	PublishDate         time.Time           `json:"publishDate" db:"publish_date"`
+	IsAutoTrimEnabled   bool                `json:"isAutoTrimEnabled" db:"is_auto_trim_enabled"`
+	GradingPriority     GradingPriority     `json:"gradingPriority" db:"grading_priority"`
+	DueDate             *time.Time          `json:"dueDate" db:"due_date"`
+	LatePolicy          LatePolicy          `json:"latePolicy" db:"late_policy"`
+	LatePenalty         float64             `json:"latePenalty" db:"late_penalty"`
+	LatePenaltyInterval LatePenaltyInterval `json:"latePenaltyInterval" db:"late_penalty_interval"`
+	LateGracePeriod     int                 `json:"lateGracePeriod" db:"late_grace_period"`
+	MaxAttempt          *int                `json:"maxAttempt" db:"max_attempt"`
+	SubmissionInterval  int                 `json:"submissionInterval" db:"submission_interval"`
+	CheckerType         CheckerType         `json:"checkerType" db:"checker_type"`
+	CheckerEpsilon      *float64            `json:"checkerEpsilon" db:"checker_epsilon"`
+	CheckerLanguage     *string             `json:"checkerLanguage" db:"checker_language"`
+	CheckerFileUrl      *string             `json:"-" db:"checker_file_url"`
+	IsDeleted           bool                `json:"-" db:"is_deleted"`
 
 	// Always aggregation
 	Testcases []Testcase `json:"testcases"`
 }
 
+// GetMaxScore prefers the score set by the instructor, then the score of the workspace level
 func (a *Assignment) GetMaxScore() float64 {
+	if a.CustomMaxScore != nil {
+		return *a.CustomMaxScore
+	}
+	if a.LevelScore != nil {
+		return *a.LevelScore
+	}
 	return assignmentScoreMap[a.Level]
 }
 
+// GetScore gives the max score in proportion to the weight of passed testcase groups.
+// A group scores only if all of its testcases pass, an ungrouped testcase is a group on its own.
+func (a *Assignment) GetScore(results []SubmissionResult) float64 {
+	type groupKey struct {
+		group      int
+		testcaseId int
+	}
+
+	isPassedById := make(map[int]bool)
+	for _, result := range results {
+		isPassedById[result.TestcaseId] = result.IsPassed
+	}
+
+	totalWeight := 0.0
+	weightByGroup := make(map[groupKey]float64)
+	isPassedByGroup := make(map[groupKey]bool)
+	for _, testcase := range a.Testcases {
+		key := groupKey{testcaseId: testcase.Id}
+		if testcase.Group != nil {
+			key = groupKey{group: *testcase.Group}
+		}
+
+		isPassed, ok := isPassedByGroup[key]
+		isPassedByGroup[key] = (!ok || isPassed) && isPassedById[testcase.Id]
+		weightByGroup[key] += testcase.Weight
+		totalWeight += testcase.Weight
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+
+	passedWeight := 0.0
+	for key, isPassed := range isPassedByGroup {
+		if isPassed {
+			passedWeight += weightByGroup[key]
+		}
+	}
+
+	score := a.GetMaxScore() * passedWeight / totalWeight
+	return math.Round(score*100) / 100
+}
+
+// GetPenalizedScore deducts the late penalty in percent from a score submitted at the given time.
+// A submission within the grace period after the due date is not penalized.
+func (a *Assignment) GetPenalizedScore(score float64, submittedAt time.Time) float64 {
+	if a.DueDate == nil {
+		return score
+	}
+
+	gracePeriod := time.Duration(a.LateGracePeriod) * time.Minute
+	lateDuration := submittedAt.Sub(a.DueDate.Add(gracePeriod))
+	if lateDuration <= 0 {
+		return score
+	}
+
+	interval := time.Hour
+	if a.LatePenaltyInterval == LatePenaltyIntervalDay {
+		interval = 24 * time.Hour
+	}
+	lateIntervals := float64(lateDuration) / float64(interval)
+
+	penalty := 0.0
+	switch a.LatePolicy {
+	case LatePolicyCutoff:
+		penalty = 100
+	case LatePolicyLinear:
+		penalty = a.LatePenalty * lateIntervals
+	case LatePolicyStep:
+		penalty = a.LatePenalty * math.Ceil(lateIntervals)
+	}
+	penalty = math.Min(penalty, 100)
+
+	return math.Round(score*(100-penalty)) / 100
+}
+
+// ApplyExtension overrides the due date and scales the time limit by the extension of a participant
+func (a *Assignment) ApplyExtension(extension *AssignmentExtension) {
+	if extension == nil {
+		return
+	}
+	if extension.DueDate != nil {
+		a.DueDate = extension.DueDate
+	}
+	a.TimeLimit = int(math.Ceil(float64(a.TimeLimit) * extension.TimeLimitMultiplier))
+}
+
+// HideTestcases removes what a member must not see from the testcases and the results of
+// the submissions graded with them, the due date is the one extended for the member
+func (a *Assignment) HideTestcases(submissions []Submission, now time.Time) {
+	isDue := a.DueDate != nil && now.After(*a.DueDate)
+
+	visibilityById := make(map[int]TestcaseVisibility, len(a.Testcases))
+	for i := range a.Testcases {
+		visibilityById[a.Testcases[i].Id] = a.Testcases[i].Visibility
+		if a.Testcases[i].Visibility != TestcaseVisibilitySample {
+			a.Testcases[i].InputFileUrl = ""
+			a.Testcases[i].OutputFileUrl = ""
+		}
+	}
+
+	for i := range submissions {
+		results := make([]SubmissionResult, 0, len(submissions[i].Results))
+		for _, result := range submissions[i].Results {
+			if visibilityById[result.TestcaseId] != TestcaseVisibilitySecret || isDue {
+				results = append(results, result)
+			}
+		}
+		submissions[i].Results = results
+	}
+}
+
+// AssignmentPackage is the manifest of an assignment archive to reuse it in another workspace,
+// files are referenced by their path in the archive
+type AssignmentPackage struct {
+	Version             int                 `json:"version"`
+	Name                string              `json:"name"`
+	Description         string              `json:"description"`
+	MemoryLimit         int                 `json:"memoryLimit"`
+	TimeLimit           int                 `json:"timeLimit"`
+	Level               AssignmentLevel     `json:"level"`
+	MaxScore            *float64            `json:"maxScore,omitempty"`
+	GradingPriority     GradingPriority     `json:"gradingPriority"`
+	PublishDate         time.Time           `json:"publishDate"`
+	DueDate             *time.Time          `json:"dueDate,omitempty"`
+	LatePolicy          LatePolicy          `json:"latePolicy"`
+	LatePenalty         float64             `json:"latePenalty"`
+	LatePenaltyInterval LatePenaltyInterval `json:"latePenaltyInterval"`
+	LateGracePeriod     int                 `json:"lateGracePeriod"`
+	MaxAttempt          *int                `json:"maxAttempt,omitempty"`
+	SubmissionInterval  int                 `json:"submissionInterval"`
+	CheckerType         CheckerType         `json:"checkerType"`
+	CheckerEpsilon      *float64            `json:"checkerEpsilon,omitempty"`
+	CheckerLanguage     *string             `json:"checkerLanguage,omitempty"`
+	CheckerFile         *string             `json:"checkerFile,omitempty"`
+	DetailFile          string              `json:"detailFile"`
+	TestcaseDirectory   string              `json:"testcaseDirectory"`
+}
+
+// AssignmentSchedule replaces the dates of an assignment reused in another workspace
+type AssignmentSchedule struct {
+	PublishDate *time.Time
+	DueDate     *time.Time
+}
+
+type AssignmentTemplate struct {
+	AssignmentId int          `json:"-" db:"assignment_id"`
+	Language     string       `json:"language" db:"language"`
+	Type         TemplateType `json:"type" db:"type"`
+	FileUrl      string       `json:"fileUrl" db:"file_url"`
+	UpdatedAt    time.Time    `json:"updatedAt" db:"updated_at"`
+}
+
 type CreateAssignment struct {
-	Name          string
-	Description   string
-	MemoryLimit   int
-	TimeLimit     int
-	Level         AssignmentLevel
-	PublishDate   time.Time
-	DueDate       *time.Time
-	DetailFile    *File
-	TestcaseFiles []TestcaseFile
+	Name                string
+	Description         string
+	MemoryLimit         int
+	TimeLimit           int
+	Level               AssignmentLevel
+	MaxScore            *float64
+	GradingPriority     *GradingPriority
+	PublishDate         time.Time
+	DueDate             *time.Time
+	LatePolicy          *LatePolicy
+	LatePenalty         *float64
+	LatePenaltyInterval *LatePenaltyInterval
+	LateGracePeriod     *int
+	MaxAttempt          *int
+	SubmissionInterval  *int
+	CheckerType         *CheckerType
+	CheckerEpsilon      *float64
+	CheckerLanguage     *string
+	CheckerFile         *File
+	DetailFile          *File
+	TestcaseFiles       []TestcaseFile
+	SolutionLanguage    *string
+	SolutionFile        *File
 }
 
 type UpdateAssignment struct {
-	Name          *string
-	Description   *string
-	MemoryLimit   *int
-	TimeLimit     *int
-	Level         *AssignmentLevel
-	PublishDate   *time.Time
-	DueDate       *time.Time
-	DetailFile    *File
-	TestcaseFiles *[]TestcaseFile
+	Name                *string
+	Description         *string
+	MemoryLimit         *int
+	TimeLimit           *int
+	Level               *AssignmentLevel
+	MaxScore            *float64
+	GradingPriority     *GradingPriority
+	PublishDate         *time.Time
+	DueDate             *time.Time
+	LatePolicy          *LatePolicy
+	LatePenalty         *float64
+	LatePenaltyInterval *LatePenaltyInterval
+	LateGracePeriod     *int
+	MaxAttempt          *int
+	SubmissionInterval  *int
+	CheckerType         *CheckerType
+	CheckerEpsilon      *float64
+	CheckerLanguage     *string
+	CheckerFile         *File
+	DetailFile          *File
+	TestcaseFiles       *[]TestcaseFile
+	SolutionLanguage    *string
+	SolutionFile        *File
 }
 
 type AssignmentWithStatus struct {
@@ -82,8 +379,20 @@ type AssignmentWithStatus struct {
 
 	MaxScore        float64          `json:"maxScore"`
 	Score           *float64         `json:"score" db:"score"`
+	AttemptCount    int              `json:"attemptCount" db:"attempt_count"`
 	Status          AssignmentStatus `json:"status" db:"status"`
 	LastSubmittedAt *time.Time       `json:"lastSubmittedAt" db:"last_submitted_at"`
+
+	// Aggregation of the extension granted to the user, already applied to the assignment
+	Extension *AssignmentExtension `json:"extension"`
+}
+
+type AssignmentExtension struct {
+	AssignmentId        int        `json:"-" db:"assignment_id"`
+	UserId              string     `json:"userId" db:"user_id"`
+	DueDate             *time.Time `json:"dueDate" db:"due_date"`
+	TimeLimitMultiplier float64    `json:"timeLimitMultiplier" db:"time_limit_multiplier"`
+	CreatedAt           time.Time  `json:"createdAt" db:"created_at"`
 }
 
 type Submission struct {
@@ -94,14 +403,47 @@ type Submission struct {
 	SubmitterProfileUrl string           `json:"submitterProfileUrl" db:"user_profile_url"`
 	Language            string           `json:"language" db:"language"`
 	Status              AssignmentStatus `json:"status" db:"status"`
+	GradingAttempt      int              `json:"-" db:"grading_attempt"`
+	GradingRetry        int              `json:"-" db:"grading_retry"`
+	GradingRequestedAt  time.Time        `json:"-" db:"grading_requested_at"`
+	TestcaseRevision    *int             `json:"testcaseRevision" db:"testcase_revision"`
 	Score               float64          `json:"score" db:"score"`
+	RawScore            float64          `json:"rawScore" db:"raw_score"`
 	FileUrl             string           `json:"fileUrl" db:"file_url"`
+	EntryPoint          *string          `json:"entryPoint" db:"entry_point"`
 	SubmittedAt         time.Time        `json:"submittedAt" db:"submitted_at"`
 	CompilationLog      *string          `json:"compilationLog,omitempty" db:"compilation_log"`
 	IsLate              bool             `json:"isLate" db:"is_late"`
 
 	// Always aggregation
 	Results []SubmissionResult `json:"results,omitempty"`
+	Files   []SubmissionFile   `json:"files,omitempty"`
+}
+
+// SubmissionSource is either a single source file or a project of files run from the entry point
+type SubmissionSource struct {
+	File       io.Reader
+	Files      []SourceFile
+	EntryPoint string
+}
+
+type SourceFile struct {
+	Path   string
+	Reader io.Reader
+	Size   int
+}
+
+type SubmissionFile struct {
+	SubmissionId int    `json:"-" db:"submission_id"`
+	Path         string `json:"path" db:"path"`
+	FileUrl      string `json:"fileUrl" db:"file_url"`
+	Size         int    `json:"size" db:"size"`
+}
+
+type SubmissionDiff struct {
+	SubmissionId      int    `json:"submissionId"`
+	OtherSubmissionId int    `json:"otherSubmissionId"`
+	Diff              string `json:"diff"`
 }
 
 type SubmissionResult struct {
@@ -113,25 +455,139 @@ type SubmissionResult struct {
 	TimeUsage    *int   `json:"timeUsage" db:"time_usage"`
 }
 
+type RegradeFilter struct {
+	IsLatestOnly bool
+	UserIds      []string
+	Status       *AssignmentStatus
+}
+
+type RegradeJob struct {
+	Id               int       `json:"id" db:"id"`
+	AssignmentId     int       `json:"assignmentId" db:"assignment_id"`
+	RequesterId      string    `json:"requesterId" db:"requester_id"`
+	TestcaseRevision int       `json:"testcaseRevision" db:"testcase_revision"`
+	Total            int       `json:"total" db:"total"`
+	Completed        int       `json:"completed" db:"completed"`
+	Failed           int       `json:"failed" db:"failed"`
+	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
+}
+
+// SimilarityJob compares the latest submissions of every user of an assignment with each other
+type SimilarityJob struct {
+	Id           int              `json:"id" db:"id"`
+	AssignmentId int              `json:"assignmentId" db:"assignment_id"`
+	RequesterId  string           `json:"requesterId" db:"requester_id"`
+	Status       SimilarityStatus `json:"status" db:"status"`
+	Total        int              `json:"total" db:"total"`
+	Log          *string          `json:"log" db:"log"`
+	CreatedAt    time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+// SimilarityPair is a pair of submissions of different users scored from 0 to 1,
+// only a pair scored at least the minimum score is stored
+type SimilarityPair struct {
+	JobId             int     `json:"-" db:"job_id"`
+	SubmissionId      int     `json:"submissionId" db:"submission_id"`
+	SubmitterId       string  `json:"submitterId" db:"user_id"`
+	OtherSubmissionId int     `json:"otherSubmissionId" db:"other_submission_id"`
+	OtherSubmitterId  string  `json:"otherSubmitterId" db:"other_user_id"`
+	Score             float64 `json:"score" db:"score"`
+}
+
+// SimilarityReport ranks the pairs of a similarity job from the most similar
+type SimilarityReport struct {
+	SimilarityJob
+	Pairs []SimilarityPair `json:"pairs"`
+}
+
+// SimilarityComparison puts the sources of a pair side by side
+type SimilarityComparison struct {
+	SimilarityPair
+	Source      []SourceContent `json:"source"`
+	OtherSource []SourceContent `json:"otherSource"`
+}
+
+// SourceContent is a file of a submission, the path of a single file submission is empty
+type SourceContent struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
 type Testcase struct {
-	Id            int    `json:"id" db:"id"`
-	AssignmentId  int    `json:"-" db:"assignment_id"`
-	Revision      int    `json:"-" db:"revision"`
-	InputFileUrl  string `json:"inputFileUrl" db:"input_file_url"`
-	OutputFileUrl string `json:"outputFileUrl" db:"output_file_url"`
+	Id            int                `json:"id" db:"id"`
+	AssignmentId  int                `json:"-" db:"assignment_id"`
+	Revision      int                `json:"revision" db:"revision"`
+	Group         *int               `json:"group" db:"testcase_group"`
+	Weight        float64            `json:"weight" db:"weight"`
+	InputFileUrl  string             `json:"inputFileUrl" db:"input_file_url"`
+	OutputFileUrl string             `json:"outputFileUrl" db:"output_file_url"`
+	Visibility    TestcaseVisibility `json:"visibility" db:"visibility"`
+}
+
+// TestcaseGeneration runs the reference solution on the inputs of a testcase revision
+// to fill in its outputs, the revision is not graded with until it is completed
+type TestcaseGeneration struct {
+	Id           int              `json:"id" db:"id"`
+	AssignmentId int              `json:"assignmentId" db:"assignment_id"`
+	Revision     int              `json:"revision" db:"revision"`
+	Language     string           `json:"language" db:"language"`
+	Status       GenerationStatus `json:"status" db:"status"`
+	Log          *string          `json:"log" db:"log"`
+	Attempt      int              `json:"-" db:"attempt"`
+	RequestedAt  time.Time        `json:"-" db:"requested_at"`
+	CreatedAt    time.Time        `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time        `json:"updatedAt" db:"updated_at"`
+}
+
+type TestcaseOutput struct {
+	TestcaseId int
+	IsPassed   bool
+	Output     string
 }
 
 type TestcaseFile struct {
-	Input  io.Reader
-	Output io.Reader
+	Input      io.Reader
+	Output     io.Reader
+	Group      *int
+	Weight     float64
+	Visibility TestcaseVisibility
+}
+
+// TestcaseMetadata describes a testcase of an archive, keyed by the number of its files
+type TestcaseMetadata struct {
+	Group      *int                `json:"group,omitempty"`
+	Weight     *float64            `json:"weight,omitempty"`
+	Visibility *TestcaseVisibility `json:"visibility,omitempty"`
 }
 
-func CreateTestcaseFiles(inputs []multipart.File, outputs []multipart.File) []TestcaseFile {
+// CreateTestcaseFiles pairs input and output files, outputs, groups, weights and visibilities
+// are optional and a testcase without them is an ungrouped sample with the weight of 1
+func CreateTestcaseFiles(
+	inputs []multipart.File,
+	outputs []multipart.File,
+	groups []int,
+	weights []float64,
+	visibilities []TestcaseVisibility,
+) []TestcaseFile {
 	files := make([]TestcaseFile, len(inputs))
 	for i, input := range inputs {
 		files[i] = TestcaseFile{
-			Input:  input,
-			Output: outputs[i],
+			Input:      input,
+			Weight:     1,
+			Visibility: TestcaseVisibilitySample,
+		}
+		if i < len(outputs) {
+			files[i].Output = outputs[i]
+		}
+		if i < len(groups) {
+			files[i].Group = &groups[i]
+		}
+		if i < len(weights) {
+			files[i].Weight = weights[i]
+		}
+		if i < len(visibilities) {
+			files[i].Visibility = visibilities[i]
 		}
 	}
 	return files
@@ -142,14 +598,51 @@ type AssignmentRepository interface {
 	Update(assignment *Assignment) error
 	Delete(id int) error
 	CreateTestcases(testcases []Testcase) error
+	CreateTestcaseGeneration(generation *TestcaseGeneration, testcases []Testcase, outbox *GradingOutbox) error
+	RequeueTestcaseGeneration(generation *TestcaseGeneration, outbox *GradingOutbox) (bool, error)
+	UpdateTestcaseGeneration(id int, status GenerationStatus, log *string) (bool, error)
+	GetTestcaseGeneration(id int) (*TestcaseGeneration, error)
+	GetLatestTestcaseGeneration(assignmentId int) (*TestcaseGeneration, error)
+	GetTestcaseRevision(assignmentId int) (int, error)
+	ListTestcase(assignmentId int, revision int) ([]Testcase, error)
+	GetTestcaseByFileUrl(assignmentId int, fileUrl string) (*Testcase, error)
 	DeleteTestcases(assignmentId int) error
-	CreateSubmission(submission *Submission, testcases []Testcase) error
-	CreateSubmissionResults(submissionId int, compilationLog string, status AssignmentStatus, score float64, results []SubmissionResult) error
+	// CreateSubmission calls checkQuota with the attempt count and the last submission time of the submitter
+	// while the submissions of the submitter are locked, the submission is not created when it returns an error
+	CreateSubmission(
+		submission *Submission,
+		outbox *GradingOutbox,
+		checkQuota func(attemptCount int, lastSubmittedAt *time.Time) error,
+	) error
+	CreateSubmissionResults(submissionId int, attempt int, compilationLog string, status AssignmentStatus, rawScore float64, score float64, results []SubmissionResult) (bool, error)
+	RequeueSubmission(submission *Submission, outbox *GradingOutbox) error
+	FailSubmission(id int) (bool, error)
+	CreateRegradeJob(job *RegradeJob, submissions []Submission, outboxes []GradingOutbox) error
+	// CreateSimilarityJob returns false without creating when a job of the assignment
+	// started within the timeout is still running
+	CreateSimilarityJob(job *SimilarityJob, timeout time.Duration) (bool, error)
+	UpdateSimilarityJob(id int, status SimilarityStatus, log *string, pairs []SimilarityPair) (bool, error)
+	UpdateExtension(extension *AssignmentExtension) error
+	DeleteExtension(assignmentId int, userId string) error
+	GetExtension(assignmentId int, userId string) (*AssignmentExtension, error)
+	ListExtension(assignmentId int) ([]AssignmentExtension, error)
+	UpdateTemplate(template *AssignmentTemplate) error
+	DeleteTemplate(assignmentId int, language string, templateType TemplateType) error
+	GetTemplate(assignmentId int, language string, templateType TemplateType) (*AssignmentTemplate, error)
+	ListTemplate(assignmentId int) ([]AssignmentTemplate, error)
 	Get(id int) (*Assignment, error)
 	GetWithStatus(id int, userId string) (*AssignmentWithStatus, error)
 	GetSubmission(id int) (*Submission, error)
+	GetRegradeJob(id int) (*RegradeJob, error)
+	GetSimilarityJob(id int) (*SimilarityJob, error)
+	GetLatestSimilarityJob(assignmentId int) (*SimilarityJob, error)
+	GetSimilarityPair(jobId int, submissionId int, otherSubmissionId int) (*SimilarityPair, error)
 	List(userId string, workspaceId int) ([]AssignmentWithStatus, error)
 	ListSubmission(userId *string, assignmentId *int) ([]Submission, error)
+	ListStuckSubmission(timeout time.Duration) ([]Submission, error)
+	ListStuckTestcaseGeneration(timeout time.Duration) ([]TestcaseGeneration, error)
+	ListRegradeSubmission(assignmentId int, filter *RegradeFilter) ([]Submission, error)
+	ListSimilarityPair(jobId int) ([]SimilarityPair, error)
 }
 
 type AssignmentUsecase interface {
@@ -157,13 +650,55 @@ type AssignmentUsecase interface {
 	Update(userId string, assignmentId int, assignment *UpdateAssignment) error
 	CreateTestcases(assignmentId int, files []TestcaseFile) error
 	UpdateTestcases(assignmentId int, files []TestcaseFile) error
+	// GenerateTestcases creates a testcase revision from inputs and requests
+	// the grader to generate its outputs with the reference solution of the language
+	GenerateTestcases(assignmentId int, files []TestcaseFile, language string) (*TestcaseGeneration, error)
+	CreateTestcaseGenerationResults(id int, compilationLog string, outputs []TestcaseOutput) error
+	GetLatestTestcaseGeneration(userId string, assignmentId int) (*TestcaseGeneration, error)
+	// ExportTestcases checks the permission and returns a writer of the latest testcases as a zip archive
+	ExportTestcases(userId string, assignmentId int) (func(w io.Writer) error, error)
+	// ExportAssignment checks the permission and returns a writer of the assignment package as a zip archive
+	ExportAssignment(userId string, assignmentId int) (func(w io.Writer) error, error)
+	ImportAssignment(userId string, workspaceId int, pkg io.ReaderAt, size int64, schedule *AssignmentSchedule) error
+	// CopyAssignment copies an assignment to another workspace for an owner of both workspaces
+	CopyAssignment(userId string, assignmentId int, workspaceId int, schedule *AssignmentSchedule) error
+	// CloneWorkspace clones a workspace along with its assignments, the schedule is shifted as a whole
+	// to start from the start date. Assignments without testcases are skipped and reported,
+	// the new workspace is deleted when any part of it cannot be cloned
+	CloneWorkspace(userId string, workspaceId int, name *string, startDate time.Time) (*ClonedWorkspace, error)
 	Delete(userId string, id int) error
-	CreateSubmission(userId string, assignmentId int, workspaceId int, language string, file io.Reader) error
-	CreateSubmissionResults(assignment *Assignment, sumbissionId int, compilationLog string, results []SubmissionResult) error
+	CreateSubmission(userId string, assignmentId int, workspaceId int, language string, source *SubmissionSource) (bool, error)
+	CreateSubmissionResults(assignment *Assignment, sumbissionId int, attempt int, compilationLog string, results []SubmissionResult) error
+	ReapSubmissions(timeout time.Duration, maxAttempt int) ([]Submission, error)
+	// ReapTestcaseGenerations requests again the generations not responded by the grader in time
+	// and returns the generations failed after all attempts are exhausted
+	ReapTestcaseGenerations(timeout time.Duration, maxAttempt int) ([]TestcaseGeneration, error)
+	Regrade(userId string, assignmentId int, filter *RegradeFilter) (*RegradeJob, error)
+	GetRegradeJob(userId string, assignmentId int, id int) (*RegradeJob, error)
+	// DetectSimilarity starts a similarity job in background on the latest submission of every user
+	DetectSimilarity(userId string, assignmentId int) (*SimilarityJob, error)
+	GetSimilarityReport(userId string, assignmentId int, id int) (*SimilarityReport, error)
+	CompareSimilarity(userId string, assignmentId int, id int, submissionId int, otherSubmissionId int) (*SimilarityComparison, error)
+	UpdateExtension(userId string, assignmentId int, extension *AssignmentExtension) error
+	DeleteExtension(userId string, assignmentId int, participantId string) error
+	ListExtension(userId string, assignmentId int) ([]AssignmentExtension, error)
+	UpdateTemplate(userId string, assignmentId int, language string, templateType TemplateType, file io.Reader) error
+	DeleteTemplate(userId string, assignmentId int, language string, templateType TemplateType) error
+	// ListTemplate returns only the starter templates to a member
+	ListTemplate(userId string, assignmentId int) ([]AssignmentTemplate, error)
 	Get(id int) (*Assignment, error)
+	// GetByRevision returns an assignment with the testcases of the given revision
+	GetByRevision(id int, revision int) (*Assignment, error)
 	GetWithStatus(id int, userId string) (*AssignmentWithStatus, error)
 	GetSubmission(id int) (*Submission, error)
+	// DiffSubmission returns the unified diff between two submissions of the same user,
+	// a member can only diff their own submissions
+	DiffSubmission(userId string, assignmentId int, submissionId int, otherSubmissionId int) (*SubmissionDiff, error)
 	List(userId string, workspaceId int) ([]AssignmentWithStatus, error)
 	ListSubmission(userId string, assignmentId int) ([]Submission, error)
 	ListAllSubmission(userId string, workspaceId int, assignmentId int) ([]Submission, error)
+	// HideSubmissionResults removes the results of secret testcases before the due date for a member
+	HideSubmissionResults(userId string, assignmentId int, submissions []Submission) error
+	// IsTestcaseFileVisible reports whether a user can fetch a testcase file, a member can fetch only samples
+	IsTestcaseFileVisible(userId string, assignmentId int, fileUrl string) (bool, error)
 }
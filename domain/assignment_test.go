@@ -0,0 +1,295 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetScore(t *testing.T) {
+	maxScore := 100.0
+	group1, group2 := 1, 2
+
+	tests := []struct {
+		name      string
+		testcases []Testcase
+		passedIds []int
+		// Results of the testcases not reported by the grader
+		missingIds []int
+		expected   float64
+	}{
+		{
+			name:      "no testcase",
+			testcases: nil,
+			expected:  0,
+		},
+		{
+			name: "ungrouped testcases by weight",
+			testcases: []Testcase{
+				{Id: 1, Weight: 1},
+				{Id: 2, Weight: 1},
+				{Id: 3, Weight: 2},
+			},
+			passedIds: []int{1, 3},
+			expected:  75,
+		},
+		{
+			name: "rounded to two decimal places",
+			testcases: []Testcase{
+				{Id: 1, Weight: 1},
+				{Id: 2, Weight: 1},
+				{Id: 3, Weight: 1},
+			},
+			passedIds: []int{1},
+			expected:  33.33,
+		},
+		{
+			name: "zero total weight",
+			testcases: []Testcase{
+				{Id: 1, Weight: 0},
+			},
+			passedIds: []int{1},
+			expected:  0,
+		},
+		{
+			name: "group scores only when all of its testcases pass",
+			testcases: []Testcase{
+				{Id: 1, Group: &group1, Weight: 1},
+				{Id: 2, Group: &group1, Weight: 1},
+				{Id: 3, Group: &group2, Weight: 2},
+			},
+			passedIds: []int{1, 3},
+			expected:  50,
+		},
+		{
+			name: "all groups pass",
+			testcases: []Testcase{
+				{Id: 1, Group: &group1, Weight: 1},
+				{Id: 2, Group: &group1, Weight: 1},
+				{Id: 3, Group: &group2, Weight: 2},
+			},
+			passedIds: []int{1, 2, 3},
+			expected:  100,
+		},
+		{
+			name: "grouped and ungrouped testcases",
+			testcases: []Testcase{
+				{Id: 1, Group: &group1, Weight: 1},
+				{Id: 2, Group: &group1, Weight: 1},
+				{Id: 3, Weight: 1},
+				{Id: 4, Weight: 1},
+			},
+			passedIds: []int{1, 2, 4},
+			expected:  75,
+		},
+		{
+			name: "testcase without result fails its group",
+			testcases: []Testcase{
+				{Id: 1, Group: &group1, Weight: 1},
+				{Id: 2, Group: &group1, Weight: 1},
+			},
+			passedIds:  []int{1},
+			missingIds: []int{2},
+			expected:   0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assignment := &Assignment{CustomMaxScore: &maxScore, Testcases: test.testcases}
+
+			isPassedById := make(map[int]bool)
+			for _, id := range test.passedIds {
+				isPassedById[id] = true
+			}
+			isMissingById := make(map[int]bool)
+			for _, id := range test.missingIds {
+				isMissingById[id] = true
+			}
+			results := make([]SubmissionResult, 0)
+			for _, testcase := range test.testcases {
+				if !isMissingById[testcase.Id] {
+					results = append(results, SubmissionResult{TestcaseId: testcase.Id, IsPassed: isPassedById[testcase.Id]})
+				}
+			}
+
+			if actual := assignment.GetScore(results); actual != test.expected {
+				t.Errorf("expected %v, actual %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetPenalizedScore(t *testing.T) {
+	dueDate := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	extendedDueDate := dueDate.Add(48 * time.Hour)
+
+	tests := []struct {
+		name        string
+		dueDate     *time.Time
+		policy      LatePolicy
+		penalty     float64
+		interval    LatePenaltyInterval
+		gracePeriod int
+		extension   *AssignmentExtension
+		submittedAt time.Time
+		expected    float64
+	}{
+		{
+			name:        "no due date",
+			policy:      LatePolicyCutoff,
+			submittedAt: dueDate.Add(24 * time.Hour),
+			expected:    80,
+		},
+		{
+			name:        "before due date",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			submittedAt: dueDate.Add(-time.Hour),
+			expected:    80,
+		},
+		{
+			name:        "at due date",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			submittedAt: dueDate,
+			expected:    80,
+		},
+		{
+			name:        "no policy",
+			dueDate:     &dueDate,
+			policy:      LatePolicyNone,
+			submittedAt: dueDate.Add(24 * time.Hour),
+			expected:    80,
+		},
+		{
+			name:        "cutoff after due date",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			submittedAt: dueDate.Add(time.Second),
+			expected:    0,
+		},
+		{
+			name:        "cutoff at end of grace period",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			gracePeriod: 30,
+			submittedAt: dueDate.Add(30 * time.Minute),
+			expected:    80,
+		},
+		{
+			name:        "cutoff after grace period",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			gracePeriod: 30,
+			submittedAt: dueDate.Add(30*time.Minute + time.Second),
+			expected:    0,
+		},
+		{
+			name:        "linear by day",
+			dueDate:     &dueDate,
+			policy:      LatePolicyLinear,
+			penalty:     10,
+			interval:    LatePenaltyIntervalDay,
+			submittedAt: dueDate.Add(36 * time.Hour),
+			expected:    68,
+		},
+		{
+			name:        "linear by hour",
+			dueDate:     &dueDate,
+			policy:      LatePolicyLinear,
+			penalty:     10,
+			interval:    LatePenaltyIntervalHour,
+			submittedAt: dueDate.Add(30 * time.Minute),
+			expected:    76,
+		},
+		{
+			name:        "linear from end of grace period",
+			dueDate:     &dueDate,
+			policy:      LatePolicyLinear,
+			penalty:     10,
+			interval:    LatePenaltyIntervalDay,
+			gracePeriod: 60,
+			submittedAt: dueDate.Add(25 * time.Hour),
+			expected:    72,
+		},
+		{
+			name:        "linear capped at full penalty",
+			dueDate:     &dueDate,
+			policy:      LatePolicyLinear,
+			penalty:     50,
+			interval:    LatePenaltyIntervalDay,
+			submittedAt: dueDate.Add(72 * time.Hour),
+			expected:    0,
+		},
+		{
+			name:        "step rounds up started interval",
+			dueDate:     &dueDate,
+			policy:      LatePolicyStep,
+			penalty:     10,
+			interval:    LatePenaltyIntervalDay,
+			submittedAt: dueDate.Add(36 * time.Hour),
+			expected:    64,
+		},
+		{
+			name:        "step within first hour",
+			dueDate:     &dueDate,
+			policy:      LatePolicyStep,
+			penalty:     10,
+			interval:    LatePenaltyIntervalHour,
+			submittedAt: dueDate.Add(time.Minute),
+			expected:    72,
+		},
+		{
+			name:        "step at end of interval",
+			dueDate:     &dueDate,
+			policy:      LatePolicyStep,
+			penalty:     10,
+			interval:    LatePenaltyIntervalHour,
+			submittedAt: dueDate.Add(2 * time.Hour),
+			expected:    64,
+		},
+		{
+			name:        "extension before extended due date",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			extension:   &AssignmentExtension{DueDate: &extendedDueDate, TimeLimitMultiplier: 1},
+			submittedAt: dueDate.Add(24 * time.Hour),
+			expected:    80,
+		},
+		{
+			name:        "extension after extended due date",
+			dueDate:     &dueDate,
+			policy:      LatePolicyLinear,
+			penalty:     10,
+			interval:    LatePenaltyIntervalDay,
+			extension:   &AssignmentExtension{DueDate: &extendedDueDate, TimeLimitMultiplier: 1},
+			submittedAt: extendedDueDate.Add(24 * time.Hour),
+			expected:    72,
+		},
+		{
+			name:        "extension without due date keeps due date",
+			dueDate:     &dueDate,
+			policy:      LatePolicyCutoff,
+			extension:   &AssignmentExtension{TimeLimitMultiplier: 2},
+			submittedAt: dueDate.Add(time.Hour),
+			expected:    0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assignment := &Assignment{
+				DueDate:             test.dueDate,
+				LatePolicy:          test.policy,
+				LatePenalty:         test.penalty,
+				LatePenaltyInterval: test.interval,
+				LateGracePeriod:     test.gracePeriod,
+			}
+			assignment.ApplyExtension(test.extension)
+
+			if actual := assignment.GetPenalizedScore(80, test.submittedAt); actual != test.expected {
+				t.Errorf("expected %v, actual %v", test.expected, actual)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ type Repository struct {
 	Workspace  WorkspaceRepository
 	Assignment AssignmentRepository
 	Survey     SurveyRepository
+	Grading    GradingRepository
+	Language   LanguageRepository
 	Misc       MiscRepository
 }
 
@@ -32,6 +34,8 @@ type Usecase struct {
 	Workspace  WorkspaceUsecase
 	Assignment AssignmentUsecase
 	Survey     SurveyUsecase
+	Grading    GradingUsecase
+	Language   LanguageUsecase
 	Misc       MiscUsecase
 }
 
@@ -29,8 +29,14 @@ const (
 	ErrCreateUser        = 2032
 	ErrUpdateUser        = 2033
 	ErrGoogleAuth        = 2040
+	ErrAdminNoPerm       = 2050
 
-	ErrGradingRequest = 4000
+	ErrGradingRequest     = 4000
+	ErrCreateDeadLetter   = 4001
+	ErrGetDeadLetter      = 4002
+	ErrListDeadLetter     = 4003
+	ErrDeadLetterNotFound = 4004
+	ErrReplayDeadLetter   = 4005
 
 	ErrFilePerm = 5000
 
@@ -53,6 +59,10 @@ const (
 	ErrUpdateWorkspace            = 30014
 	ErrDeleteWorkspace            = 30015
 	ErrWorkspaceAlreadyJoin       = 30016
+	ErrListWorkspaceLevel         = 30017
+	ErrUpdateWorkspaceLevel       = 30018
+	ErrDeleteWorkspaceLevel       = 30019
+	ErrLevelInUse                 = 30020
 
 	ErrCreateInvitation      = 31000
 	ErrGetInvitation         = 31001
@@ -67,15 +77,67 @@ const (
 	ErrAssignmentNoTestcase = 40003
 	ErrCreateAssignment     = 40004
 	ErrUpdateAssignment     = 40005
+	ErrInvalidLevel         = 40006
+	ErrInvalidChecker       = 40007
+	ErrInvalidPackage       = 40008
+	ErrExportPackage        = 40009
 
 	ErrCreateSubmission       = 41000
 	ErrCreateSubmissionResult = 41001
 	ErrGetSubmission          = 41002
 	ErrListSubmission         = 41003
+	ErrUpdateSubmission       = 41004
+	ErrRequeueSubmission      = 41005
+	ErrDupSubmissionResult    = 41006
+	ErrCreateRegradeJob       = 41007
+	ErrGetRegradeJob          = 41008
+	ErrRegradeJobNotFound     = 41009
+	ErrSubmissionNoAttempt    = 41010
+	ErrSubmissionTooFrequent  = 41011
+	ErrSubmissionRateLimited  = 41012
+	ErrInvalidSubmissionFile  = 41013
+	ErrSubmissionTooLarge     = 41014
+	ErrSubmissionNotFound     = 41015
+	ErrInvalidSubmissionDiff  = 41016
 
 	ErrListTestcase   = 42000
 	ErrCreateTestcase = 42001
 	ErrDeleteTestcase = 42002
 
+	ErrCreateTestcaseGeneration   = 42003
+	ErrUpdateTestcaseGeneration   = 42004
+	ErrGetTestcaseGeneration      = 42005
+	ErrTestcaseGenerationNotFound = 42006
+	ErrDupTestcaseGeneration      = 42007
+
+	ErrInvalidTestcaseArchive = 42008
+	ErrExportTestcase         = 42009
+
+	ErrGetLanguage      = 43000
+	ErrListLanguage     = 43001
+	ErrLanguageNotFound = 43002
+	ErrLanguageDisabled = 43003
+	ErrUpdateLanguage   = 43004
+
+	ErrUpdateExtension   = 44000
+	ErrDeleteExtension   = 44001
+	ErrListExtension     = 44002
+	ErrExtensionNotFound = 44003
+	ErrExtensionNoUser   = 44004
+
+	ErrUpdateTemplate   = 45000
+	ErrDeleteTemplate   = 45001
+	ErrListTemplate     = 45002
+	ErrTemplateNotFound = 45003
+
+	ErrCreateSimilarityJob    = 46000
+	ErrUpdateSimilarityJob    = 46001
+	ErrGetSimilarityJob       = 46002
+	ErrSimilarityJobNotFound  = 46003
+	ErrDupSimilarityJob       = 46004
+	ErrGetSimilarityPair      = 46005
+	ErrListSimilarityPair     = 46006
+	ErrSimilarityPairNotFound = 46007
+
 	ErrCreateSurvey = 50000
 )
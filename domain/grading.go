@@ -1,7 +1,63 @@
 package domain
 
+import "time"
+
+// GradingOutbox is a request of either grading a submission or generating testcase outputs
+type GradingOutbox struct {
+	Id            int        `db:"id"`
+	SubmissionId  *int       `db:"submission_id"`
+	GenerationId  *int       `db:"generation_id"`
+	Exchange      string     `db:"exchange"`
+	RoutingKey    string     `db:"routing_key"`
+	Body          []byte     `db:"body"`
+	Priority      uint8      `db:"priority"`
+	Attempt       int        `db:"attempt"`
+	LastError     *string    `db:"last_error"`
+	CreatedAt     time.Time  `db:"created_at"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	SentAt        *time.Time `db:"sent_at"`
+}
+
+type DeadLetter struct {
+	Id         int        `json:"id" db:"id"`
+	Queue      string     `json:"queue" db:"queue"`
+	Reason     string     `json:"reason" db:"reason"`
+	Body       string     `json:"body" db:"body"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	ReplayedAt *time.Time `json:"replayedAt" db:"replayed_at"`
+}
+
+type GradingRepository interface {
+	// ProcessOutbox locks up to limit pending outbox messages and calls fn on each of them.
+	// A message is marked as sent when fn succeeds, otherwise it is retried later
+	// with an exponential backoff capped at maxBackoff.
+	ProcessOutbox(limit int, maxBackoff time.Duration, fn func(outbox *GradingOutbox) error) error
+	// SendOutbox calls fn on a single pending outbox message and reports whether it is sent.
+	// It does nothing when the message is already sent or locked by the relay.
+	SendOutbox(id int, maxBackoff time.Duration, fn func(outbox *GradingOutbox) error) (bool, error)
+	GetLatestOutbox(submissionId int) (*GradingOutbox, error)
+	CreateDeadLetter(deadLetter *DeadLetter) error
+	GetDeadLetter(id int) (*DeadLetter, error)
+	ListDeadLetter() ([]DeadLetter, error)
+	UpdateDeadLetterReplayed(id int) error
+}
+
+type GradingUsecase interface {
+	CreateDeadLetter(queue string, reason string, body []byte) error
+	GetDeadLetter(id int) (*DeadLetter, error)
+	ListDeadLetter() ([]DeadLetter, error)
+	ReplayDeadLetter(id int) error
+}
+
 type GradingPublisher interface {
-	Grade(assignment *AssignmentWithStatus, submission *Submission) error
+	Grade(assignment *AssignmentWithStatus, submission *Submission, language *Language) error
+	// CreateOutbox builds a grading request routed by the routing key of the language,
+	// the default routing key is used when language is nil
+	CreateOutbox(assignment *AssignmentWithStatus, submission *Submission, language *Language) (*GradingOutbox, error)
+	PublishOutbox(outbox *GradingOutbox) error
+	// CreateGenerationOutbox builds a request of the outputs of the testcases from the reference solution,
+	// it is routed to the generation queue regardless of the language
+	CreateGenerationOutbox(generation *TestcaseGeneration, assignment *Assignment, testcases []Testcase, solution *AssignmentTemplate, language *Language) (*GradingOutbox, error)
 }
 
 type GradingConsumer interface {
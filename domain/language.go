@@ -0,0 +1,25 @@
+package domain
+
+type Language struct {
+	Id              string `json:"id" db:"id"`
+	Name            string `json:"name" db:"name"`
+	CompilerVersion string `json:"compilerVersion" db:"compiler_version"`
+	FileExtension   string `json:"fileExtension" db:"file_extension"`
+	RoutingKey      string `json:"-" db:"routing_key"`
+	IsEnabled       bool   `json:"isEnabled" db:"is_enabled"`
+}
+
+type LanguageRepository interface {
+	Get(id string, workspaceId int) (*Language, error)
+	List() ([]Language, error)
+	ListByWorkspace(workspaceId int) ([]Language, error)
+	UpdateWorkspaceLanguage(workspaceId int, languageId string, isEnabled bool) error
+}
+
+type LanguageUsecase interface {
+	// Get returns a language with the enabled flag of the workspace applied
+	Get(id string, workspaceId int) (*Language, error)
+	List() ([]Language, error)
+	ListByWorkspace(workspaceId int) ([]Language, error)
+	UpdateWorkspaceLanguage(userId string, workspaceId int, languageId string, isEnabled bool) error
+}
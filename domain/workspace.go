@@ -19,6 +19,12 @@ type RawWorkspace struct {
 	IsDeleted        bool      `json:"-" db:"is_deleted"`
 }
 
+// ClonedWorkspace is a cloned workspace with the ids of the assignments skipped for having no testcases
+type ClonedWorkspace struct {
+	RawWorkspace
+	SkippedAssignmentIds []int `json:"skippedAssignmentIds"`
+}
+
 type Workspace struct {
 	RawWorkspace
 
@@ -88,6 +94,12 @@ type WorkspaceRank struct {
 	LastSubmittedAt     string  `json:"lastSubmittedAt" db:"last_submitted_at"`
 }
 
+type WorkspaceLevel struct {
+	WorkspaceId int             `json:"-" db:"workspace_id"`
+	Level       AssignmentLevel `json:"level" db:"level"`
+	Score       float64         `json:"score" db:"score"`
+}
+
 type WorkspaceRepository interface {
 	Create(userId string, workspace *RawWorkspace) error
 	CreateInvitation(invitation *WorkspaceInvitation) error
@@ -102,12 +114,16 @@ type WorkspaceRepository interface {
 	GetScoreboard(workspaceId int) ([]WorkspaceRank, error)
 	List(userId string) ([]Workspace, error)
 	ListParticipant(workspaceId int) ([]WorkspaceParticipant, error)
+	ListLevel(workspaceId int) ([]WorkspaceLevel, error)
 	Update(userId string, workspace *Workspace) error
 	UpdateRecent(userId string, workspaceId int) error
 	UpdateParticipant(userId string, workspaceId int, participant *WorkspaceParticipant) error
+	UpdateLevel(level *WorkspaceLevel) error
 	Delete(workspaceId int) error
 	DeleteInvitation(invitationId string) error
 	DeleteParticipant(workspaceId int, userId string) error
+	// DeleteLevel returns false without deleting when the level is used by any assignment
+	DeleteLevel(workspaceId int, level AssignmentLevel) (bool, error)
 }
 
 type WorkspaceUsecase interface {
@@ -127,10 +143,16 @@ type WorkspaceUsecase interface {
 	CheckPermRole(userId string, workspaceId int, roles []WorkspaceRole) (bool, error)
 	List(userId string) ([]Workspace, error)
 	ListParticipant(workspaceId int) ([]WorkspaceParticipant, error)
+	// ListLevel returns the default levels merged with the custom levels of a workspace
+	ListLevel(workspaceId int) ([]WorkspaceLevel, error)
 	Update(userId string, workspaceId int, workspace *UpdateWorkspace) error
 	Favorite(userId string, workspaceId int, favorite bool) error
 	UpdateParticipant(updaterUserId string, targetUserId string, workspaceId int, role *UpdateParticipant) error
+	UpdateLevel(userId string, workspaceId int, level AssignmentLevel, score float64) error
+	// Clone creates a workspace with the profile, the admins, the owners and the levels of a workspace
+	Clone(userId string, workspaceId int, name *string) (*RawWorkspace, error)
 	Delete(userId string, workspaceId int) error
 	DeleteInvitation(invitationId string, userId string) error
 	DeleteParticipant(workspaceId int, removerUserId, targetUserId string) error
+	DeleteLevel(userId string, workspaceId int, level AssignmentLevel) error
 }
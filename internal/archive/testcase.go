@@ -0,0 +1,100 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
+)
+
+// ReadTestcases reads testcases from N.in and N.out files numbered from 1 with optional metadata
+// of each testcase keyed by its number, outputs are optional only when they are generated from a reference solution
+func ReadTestcases(files map[string][]byte, isOutputRequired bool) ([]domain.TestcaseFile, error) {
+	inputs := make(map[int][]byte)
+	outputs := make(map[int][]byte)
+	metadata := make(map[string]domain.TestcaseMetadata)
+	for filePath, content := range files {
+		// Testcases can be zipped with their parent directory
+		name := path.Base(filePath)
+		if name == constant.TestcaseMetadataFileName {
+			if err := json.Unmarshal(content, &metadata); err != nil {
+				return nil, errs.New(errs.ErrInvalidTestcaseArchive, "cannot parse %s", name, err)
+			}
+			continue
+		}
+
+		number, ext, _ := strings.Cut(name, ".")
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 {
+			continue
+		}
+		switch ext {
+		case "in":
+			inputs[n] = content
+		case "out":
+			outputs[n] = content
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, errs.New(errs.ErrInvalidTestcaseArchive, "archive has no testcase input")
+	}
+	for number := range outputs {
+		if _, ok := inputs[number]; !ok {
+			return nil, errs.New(errs.ErrInvalidTestcaseArchive, "archive has %d.out without %d.in", number, number)
+		}
+	}
+
+	testcases := make([]domain.TestcaseFile, len(inputs))
+	for i := range testcases {
+		number := i + 1
+		input, ok := inputs[number]
+		if !ok {
+			return nil, errs.New(errs.ErrInvalidTestcaseArchive, "archive has no %d.in", number)
+		}
+		testcases[i] = domain.TestcaseFile{
+			Input:      bytes.NewReader(input),
+			Weight:     1,
+			Visibility: domain.TestcaseVisibilitySample,
+		}
+		if output, ok := outputs[number]; ok {
+			testcases[i].Output = bytes.NewReader(output)
+		} else if isOutputRequired {
+			return nil, errs.New(errs.ErrInvalidTestcaseArchive, "archive has no %d.out", number)
+		}
+
+		if err := applyTestcaseMetadata(&testcases[i], metadata[strconv.Itoa(number)]); err != nil {
+			return nil, errs.New(errs.SameCode, "invalid metadata of testcase %d", number, err)
+		}
+	}
+	return testcases, nil
+}
+
+func applyTestcaseMetadata(testcase *domain.TestcaseFile, metadata domain.TestcaseMetadata) error {
+	if metadata.Group != nil {
+		if *metadata.Group < 1 {
+			return errs.New(errs.ErrInvalidTestcaseArchive, "group must be at least 1")
+		}
+		testcase.Group = metadata.Group
+	}
+	if metadata.Weight != nil {
+		if *metadata.Weight <= 0 {
+			return errs.New(errs.ErrInvalidTestcaseArchive, "weight must be greater than 0")
+		}
+		testcase.Weight = *metadata.Weight
+	}
+	if metadata.Visibility != nil {
+		switch *metadata.Visibility {
+		case domain.TestcaseVisibilitySample, domain.TestcaseVisibilityHidden, domain.TestcaseVisibilitySecret:
+			testcase.Visibility = *metadata.Visibility
+		default:
+			return errs.New(errs.ErrInvalidTestcaseArchive, "unknown visibility %s", *metadata.Visibility)
+		}
+	}
+	return nil
+}
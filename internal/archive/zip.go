@@ -0,0 +1,50 @@
+package archive
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+)
+
+// ReadZip reads every file of a zip archive into memory by its path,
+// stopping at the limit of the total size to not inflate a zip bomb
+func ReadZip(reader io.ReaderAt, size int64, limit int64) (map[string][]byte, error) {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read zip archive: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	remainingSize := limit
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		content, err := readEntry(entry, remainingSize)
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(content)) > remainingSize {
+			return nil, fmt.Errorf("zip archive exceeds %d bytes", limit)
+		}
+		remainingSize -= int64(len(content))
+
+		files[entry.Name] = content
+	}
+	return files, nil
+}
+
+func readEntry(entry *zip.File, limit int64) ([]byte, error) {
+	file, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s in zip archive: %w", entry.Name, err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s in zip archive: %w", entry.Name, err)
+	}
+	return content, nil
+}
@@ -14,6 +14,7 @@ type Config struct {
 	Client   ConfigClient   `yaml:"client" validate:"required"`
 	Google   ConfigGoogle   `yaml:"google" validate:"required"`
 	Auth     ConfigAuth     `yaml:"auth" validate:"required"`
+	Grading  ConfigGrading  `yaml:"grading"`
 }
 
 type ConfigMetadata struct {
@@ -78,6 +79,7 @@ type ConfigGoogle struct {
 
 type ConfigAuth struct {
 	Session ConfigAuthSession `yaml:"session" validate:"required"`
+	Admins  []string          `yaml:"admins"`
 }
 
 type ConfigAuthSession struct {
@@ -86,6 +88,35 @@ type ConfigAuthSession struct {
 	MaxAge int    `yaml:"maxAge" validate:"number,required"`
 }
 
+type ConfigGrading struct {
+	Reaper    ConfigGradingReaper    `yaml:"reaper" validate:"required"`
+	RateLimit ConfigGradingRateLimit `yaml:"rateLimit" validate:"required"`
+}
+
+type ConfigGradingReaper struct {
+	Interval   int `yaml:"interval" validate:"number,required"`
+	Deadline   int `yaml:"deadline" validate:"number,required"`
+	MaxAttempt int `yaml:"maxAttempt" validate:"number,required"`
+}
+
+type ConfigGradingRateLimit struct {
+	Capacity int `yaml:"capacity" validate:"number,required"`
+	Interval int `yaml:"interval" validate:"number,required"`
+}
+
+// Grading settings are optional to keep a config file written before them working
+var defaultConfigGrading = ConfigGrading{
+	Reaper: ConfigGradingReaper{
+		Interval:   60,
+		Deadline:   600,
+		MaxAttempt: 3,
+	},
+	RateLimit: ConfigGradingRateLimit{
+		Capacity: 60,
+		Interval: 1,
+	},
+}
+
 func Load(path string) (*Config, error) {
 	if err := validatePath(path); err != nil {
 		return nil, err
@@ -97,9 +128,10 @@ func Load(path string) (*Config, error) {
 	}
 	defer file.Close()
 
-	var config *Config
+	// Fields missing from the file are left as their defaults
+	config := &Config{Grading: defaultConfigGrading}
 	decoder := yaml.NewDecoder(file)
-	if err := decoder.Decode(&config); err != nil {
+	if err := decoder.Decode(config); err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,9 @@
 package constant
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 var (
 	Version       = "0.0.0" // Load from LDFLAGS for versioning
@@ -19,5 +22,40 @@ var (
 
 	MaxInvitationCodeChar = 6
 
+	MaxSubmissionFileCount = 32
+	MaxSubmissionSize      = 1048576 // 1 MiB in total of all files
+	SubmissionDiffContext  = 3
+
+	MaxTestcaseArchiveSize   = 67108864 // 64 MiB in total of all files
+	TestcaseMetadataFileName = "testcases.json"
+
+	AssignmentPackageVersion  = 1
+	MaxAssignmentPackageSize  = 134217728 // 128 MiB in total of all files
+	AssignmentPackageManifest = "assignment.json"
+
+	GradingExchange           = "grading.v2"
+	GradingRequestRoutingKey  = "request"
+	GradingResponseQueue      = "grading_response"
+	GenerationRoutingKey      = "generate"
+	GenerationResponseQueue   = "generation_response"
+	GradingDeadLetterExchange = "grading.dlx"
+	GradingDeadLetterQueue    = "grading_dead_letter"
+	GradingMaxPriority        = 10
+
+	DefaultCheckerEpsilon = 1e-6
+
+	SimilarityKGramSize  = 5
+	SimilarityWindowSize = 4
+	MinSimilarityScore   = 0.1
+	SimilarityJobTimeout = 1 * time.Hour
+
+	RabbitMqReconnectMinBackoff   = 1 * time.Second
+	RabbitMqReconnectMaxBackoff   = 30 * time.Second
+	RabbitMqPublishConfirmTimeout = 5 * time.Second
+
+	GradingOutboxInterval   = 1 * time.Second
+	GradingOutboxBatchSize  = 50
+	GradingOutboxMaxBackoff = 5 * time.Minute
+
 	DefaultProfileUrl = "/workspaces/1/profile"
 )
@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type operation int
+
+const (
+	equal operation = iota
+	deletion
+	insertion
+)
+
+// maxEdits caps the edit distance to be searched, as backtracking keeps every step of the search
+// the memory grows with the square of the distance
+const maxEdits = 1000
+
+// edit is a line of a diff with the number of lines before it in both texts
+type edit struct {
+	operation operation
+	oldLine   int
+	newLine   int
+	text      string
+}
+
+// Unified returns the unified diff of two texts by line with the lines of context around changes,
+// an empty string is returned when both texts are the same, and only a line telling the texts differ
+// is returned when they are too different to be compared
+func Unified(oldName string, newName string, oldText string, newText string, context int) string {
+	edits, ok := diffLines(splitLines(oldText), splitLines(newText))
+	if !ok {
+		return fmt.Sprintf("Files %s and %s differ\n", oldName, newName)
+	}
+
+	var out strings.Builder
+	for start := 0; start < len(edits); {
+		for start < len(edits) && edits[start].operation == equal {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// Changes separated by no more than twice the context are in the same hunk
+		end := start + 1
+		for i := start; i < len(edits) && i-end <= 2*context; i++ {
+			if edits[i].operation != equal {
+				end = i + 1
+			}
+		}
+		hunkStart := max(start-context, 0)
+		hunkEnd := min(end+context, len(edits))
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&out, edits[hunkStart:hunkEnd])
+		start = hunkEnd
+	}
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, edits []edit) {
+	oldCount, newCount := 0, 0
+	for _, e := range edits {
+		if e.operation != insertion {
+			oldCount++
+		}
+		if e.operation != deletion {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before it
+	oldStart, newStart := edits[0].oldLine, edits[0].newLine
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+
+	for _, e := range edits {
+		prefix := " "
+		switch e.operation {
+		case deletion:
+			prefix = "-"
+		case insertion:
+			prefix = "+"
+		}
+		out.WriteString(prefix)
+		out.WriteString(e.text)
+		out.WriteString("\n")
+	}
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines finds the shortest edit script between lines by the Myers algorithm,
+// false is returned when the script is longer than maxEdits
+func diffLines(a []string, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil, true
+	}
+	offset := n + m
+	v := make([]int, 2*offset+2)
+
+	// Only the diagonals reachable in each step are kept to backtrack the edits
+	trace := make([][]int, 0)
+	for d := 0; d <= min(n+m, maxEdits); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(trace, a, b), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(trace [][]int, a []string, b []string) []edit {
+	edits := make([]edit, 0, len(a)+len(b))
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{operation: equal, oldLine: x, newLine: y, text: a[x]})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{operation: insertion, oldLine: x, newLine: y, text: b[y]})
+		} else {
+			x--
+			edits = append(edits, edit{operation: deletion, oldLine: x, newLine: y, text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{operation: equal, oldLine: x, newLine: y, text: a[x]})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldName  string
+		newName  string
+		oldText  string
+		newText  string
+		context  int
+		expected string
+	}{
+		{
+			name:     "empty texts",
+			oldName:  "a",
+			newName:  "b",
+			expected: "",
+		},
+		{
+			name:     "same texts",
+			oldName:  "a",
+			newName:  "b",
+			oldText:  "x\ny\n",
+			newText:  "x\ny\n",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:     "same texts with different line endings",
+			oldName:  "a",
+			newName:  "b",
+			oldText:  "x\r\ny\r\n",
+			newText:  "x\ny",
+			context:  3,
+			expected: "",
+		},
+		{
+			name:     "added file",
+			oldName:  "/dev/null",
+			newName:  "b",
+			newText:  "x\ny\n",
+			context:  3,
+			expected: "--- /dev/null\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name:     "removed file",
+			oldName:  "a",
+			newName:  "/dev/null",
+			oldText:  "x\ny\n",
+			context:  3,
+			expected: "--- a\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-x\n-y\n",
+		},
+		{
+			name:     "changed line with context",
+			oldName:  "a",
+			newName:  "b",
+			oldText:  "1\n2\n3\n4\n5\n",
+			newText:  "1\n2\nx\n4\n5\n",
+			context:  1,
+			expected: "--- a\n+++ b\n@@ -2,3 +2,3 @@\n 2\n-3\n+x\n 4\n",
+		},
+		{
+			name:     "inserted line",
+			oldName:  "a",
+			newName:  "b",
+			oldText:  "1\n2\n",
+			newText:  "1\nx\n2\n",
+			context:  0,
+			expected: "--- a\n+++ b\n@@ -1,0 +2,1 @@\n+x\n",
+		},
+		{
+			name:     "changes within twice the context in one hunk",
+			oldName:  "a",
+			newName:  "b",
+			oldText:  "1\n2\n3\n4\n5\n6\n",
+			newText:  "x\n2\n3\n4\n5\ny\n",
+			context:  2,
+			expected: "--- a\n+++ b\n@@ -1,6 +1,6 @@\n-1\n+x\n 2\n 3\n 4\n 5\n-6\n+y\n",
+		},
+		{
+			name:     "changes beyond twice the context in separate hunks",
+			oldName:  "a",
+			newName:  "b",
+			oldText:  "1\n2\n3\n4\n5\n6\n",
+			newText:  "x\n2\n3\n4\n5\ny\n",
+			context:  1,
+			expected: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -5,2 +5,2 @@\n 5\n-6\n+y\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Unified(test.oldName, test.newName, test.oldText, test.newText, test.context)
+			if actual != test.expected {
+				t.Errorf("expected:\n%s\nactual:\n%s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestUnifiedEditCap(t *testing.T) {
+	tests := []struct {
+		name      string
+		lineCount int
+		isCapped  bool
+	}{
+		{name: "edits within the cap", lineCount: maxEdits / 2, isCapped: false},
+		{name: "edits beyond the cap", lineCount: maxEdits, isCapped: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Every line is replaced, so the edit distance is twice the line count
+			oldLines := make([]string, test.lineCount)
+			newLines := make([]string, test.lineCount)
+			for i := range oldLines {
+				oldLines[i] = "old " + strconv.Itoa(i)
+				newLines[i] = "new " + strconv.Itoa(i)
+			}
+
+			actual := Unified("a", "b", strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"), 3)
+			isCapped := actual == "Files a and b differ\n"
+			if isCapped != test.isCapped {
+				t.Errorf("expected capped %t, actual output starts with %.40q", test.isCapped, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket limits actions per key, a key can burst up to the capacity
+// and regains a token every interval
+type TokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	interval time.Duration
+	buckets  map[string]*bucket
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func NewTokenBucket(capacity int, interval time.Duration) *TokenBucket {
+	return &TokenBucket{
+		capacity: float64(capacity),
+		interval: interval,
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token of the key and reports whether there was one left
+func (b *TokenBucket) Allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	bk, ok := b.buckets[key]
+	if !ok {
+		bk = &bucket{tokens: b.capacity, updatedAt: now}
+		b.buckets[key] = bk
+	}
+
+	refilled := float64(now.Sub(bk.updatedAt)) / float64(b.interval)
+	bk.tokens = min(b.capacity, bk.tokens+refilled)
+	bk.updatedAt = now
+
+	if bk.tokens < 1 {
+		return false
+	}
+	bk.tokens -= 1
+	return true
+}
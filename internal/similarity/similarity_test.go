@@ -0,0 +1,130 @@
+package similarity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{
+			name:     "identifiers and literals",
+			source:   `total = count + 1.5 * "text"`,
+			expected: []string{"V", "=", "V", "+", "N", "*", "S"},
+		},
+		{
+			name:     "keywords are kept",
+			source:   "for i in range(n): return i",
+			expected: []string{"for", "V", "in", "range", "(", "V", ")", ":", "return", "V"},
+		},
+		{
+			name:     "comments and directives are dropped",
+			source:   "#include <stdio.h>\n// line\nx /* block */ = 'c'; # python",
+			expected: []string{"V", "=", "S", ";"},
+		},
+		{
+			name:     "escaped quote in string",
+			source:   `s = "a\"b"`,
+			expected: []string{"V", "=", "S"},
+		},
+		{
+			name:     "empty source",
+			source:   "",
+			expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Tokenize([]byte(test.source))
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, actual %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	const (
+		k      = 5
+		window = 4
+	)
+	source := `
+def solve(numbers):
+    total = 0
+    for number in numbers:
+        if number % 2 == 0:
+            total += number
+    return total
+`
+	renamed := `
+def answer(values):
+    result = 0
+    for value in values:
+        if value % 3 == 1:
+            result += value
+    return result
+`
+	different := `
+class Stack:
+    def __init__(self):
+        self.items = []
+    def push(self, item):
+        self.items.append(item)
+`
+
+	tests := []struct {
+		name     string
+		source   string
+		other    string
+		minScore float64
+		maxScore float64
+	}{
+		{name: "identical sources", source: source, other: source, minScore: 1, maxScore: 1},
+		{name: "renamed identifiers and constants", source: source, other: renamed, minScore: 1, maxScore: 1},
+		{name: "different sources", source: source, other: different, minScore: 0, maxScore: 0.5},
+		{name: "source shorter than a k-gram", source: "x = 1", other: "y = 2", minScore: 1, maxScore: 1},
+		{name: "source shorter than a window", source: "a = b + c", other: "d = e + f", minScore: 1, maxScore: 1},
+		{name: "empty source", source: "", other: source, minScore: 0, maxScore: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fingerprint := Winnow(Tokenize([]byte(test.source)), k, window)
+			other := Winnow(Tokenize([]byte(test.other)), k, window)
+
+			score := fingerprint.Similarity(other)
+			if score < test.minScore || score > test.maxScore {
+				t.Errorf("expected score from %v to %v, actual %v", test.minScore, test.maxScore, score)
+			}
+			if reverse := other.Similarity(fingerprint); reverse != score {
+				t.Errorf("expected symmetric score %v, actual %v", score, reverse)
+			}
+		})
+	}
+}
+
+func TestWinnowShortTokens(t *testing.T) {
+	tests := []struct {
+		name     string
+		tokens   []string
+		expected int
+	}{
+		{name: "no token", tokens: nil, expected: 0},
+		{name: "fewer tokens than a k-gram", tokens: []string{"V", "="}, expected: 1},
+		{name: "fewer k-grams than a window", tokens: []string{"V", "=", "V", "+", "N", ";"}, expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := Winnow(test.tokens, 5, 4)
+			if len(actual) != test.expected {
+				t.Errorf("expected %d hashes, actual %d", test.expected, len(actual))
+			}
+		})
+	}
+}
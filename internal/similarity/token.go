@@ -0,0 +1,111 @@
+package similarity
+
+// Tokens replacing identifiers, numbers and string literals, so renaming variables
+// or changing constants does not hide a copied source
+const (
+	identifierToken = "V"
+	numberToken     = "N"
+	stringToken     = "S"
+)
+
+// Keywords of the supported languages are kept as is to preserve the structure of a source
+var keywords = map[string]bool{
+	"if": true, "else": true, "elif": true, "for": true, "while": true, "do": true,
+	"switch": true, "case": true, "default": true, "break": true, "continue": true,
+	"return": true, "goto": true, "try": true, "catch": true, "except": true,
+	"finally": true, "throw": true, "raise": true, "with": true, "pass": true,
+	"yield": true, "lambda": true, "def": true, "func": true, "function": true,
+	"class": true, "struct": true, "enum": true, "union": true, "interface": true,
+	"new": true, "delete": true, "import": true, "from": true, "include": true,
+	"using": true, "namespace": true, "package": true, "in": true, "is": true,
+	"not": true, "and": true, "or": true, "const": true, "static": true,
+	"var": true, "let": true, "auto": true, "void": true, "int": true, "long": true,
+	"short": true, "char": true, "float": true, "double": true, "bool": true,
+	"boolean": true, "string": true, "unsigned": true, "signed": true,
+	"true": true, "false": true, "True": true, "False": true, "None": true,
+	"null": true, "nullptr": true, "self": true, "this": true, "range": true,
+}
+
+// Tokenize splits a source into normalized tokens, whitespaces and comments are dropped
+// regardless of the language, and so are preprocessor directives along with Python comments
+func Tokenize(source []byte) []string {
+	tokens := make([]string, 0, len(source)/4)
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '#' || hasPrefix(source, i, "//"):
+			i = skipUntil(source, i, "\n")
+		case hasPrefix(source, i, "/*"):
+			i = skipUntil(source, i+2, "*/")
+		case c == '"' || c == '\'' || c == '`':
+			i = skipString(source, i)
+			tokens = append(tokens, stringToken)
+		case isDigit(c):
+			for i < len(source) && (isIdentifier(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, numberToken)
+		case isIdentifier(c):
+			start := i
+			for i < len(source) && isIdentifier(source[i]) {
+				i++
+			}
+			word := string(source[start:i])
+			if keywords[word] {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, identifierToken)
+			}
+		default:
+			tokens = append(tokens, string(c))
+			i++
+		}
+	}
+	return tokens
+}
+
+func skipUntil(source []byte, i int, end string) int {
+	for ; i < len(source); i++ {
+		if hasPrefix(source, i, end) {
+			return i + len(end)
+		}
+	}
+	return len(source)
+}
+
+// skipString skips a string literal until its unescaped quote or the end of the line
+func skipString(source []byte, i int) int {
+	quote := source[i]
+	for i++; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i + 1
+			}
+		}
+	}
+	return len(source)
+}
+
+func hasPrefix(source []byte, i int, prefix string) bool {
+	return len(source)-i >= len(prefix) && string(source[i:i+len(prefix)]) == prefix
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentifier also accepts any byte of a multi-byte character to support unicode identifiers
+func isIdentifier(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
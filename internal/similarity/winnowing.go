@@ -0,0 +1,68 @@
+package similarity
+
+import "hash/fnv"
+
+// Fingerprint is a set of hashes selected from the k-grams of tokens
+type Fingerprint map[uint64]struct{}
+
+// Winnow fingerprints tokens by selecting the minimum hash of k-grams in every window,
+// any run of at least window+k-1 tokens shared by two sources is guaranteed to be detected
+func Winnow(tokens []string, k int, window int) Fingerprint {
+	fingerprint := make(Fingerprint)
+	if len(tokens) == 0 {
+		return fingerprint
+	}
+	// A source shorter than a k-gram is fingerprinted as a whole
+	if len(tokens) < k {
+		k = len(tokens)
+	}
+
+	hashes := make([]uint64, len(tokens)-k+1)
+	for i := range hashes {
+		hashes[i] = hashKGram(tokens[i : i+k])
+	}
+	if len(hashes) < window {
+		window = len(hashes)
+	}
+
+	selected := -1
+	for start := 0; start+window <= len(hashes); start++ {
+		// The rightmost minimum is preferred to select the same k-gram across windows
+		minimum := start
+		for i := start + 1; i < start+window; i++ {
+			if hashes[i] <= hashes[minimum] {
+				minimum = i
+			}
+		}
+		if minimum != selected {
+			selected = minimum
+			fingerprint[hashes[minimum]] = struct{}{}
+		}
+	}
+	return fingerprint
+}
+
+// Similarity returns the Jaccard index of two fingerprints from 0 to 1
+func (f Fingerprint) Similarity(other Fingerprint) float64 {
+	if len(f) == 0 || len(other) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for hash := range f {
+		if _, ok := other[hash]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(f)+len(other)-shared)
+}
+
+func hashKGram(tokens []string) uint64 {
+	hash := fnv.New64a()
+	for _, token := range tokens {
+		hash.Write([]byte(token))
+		// Separates tokens to not hash "a" "bc" the same as "ab" "c"
+		hash.Write([]byte{0})
+	}
+	return hash.Sum64()
+}
@@ -120,6 +120,16 @@ func fileParser(payload interface{}, ctx *fiber.Ctx) error {
 					v.Field(i).Set(reflect.ValueOf(files))
 					break
 				}
+			} else if field.Type == reflect.TypeOf((*multipart.FileHeader)(nil)) {
+				// Parse a single file header to keep the file name and size
+				if headers, ok := form.File[fileKey]; ok {
+					v.Field(i).Set(reflect.ValueOf(headers[0]))
+				}
+			} else if field.Type == reflect.TypeOf(([]*multipart.FileHeader)(nil)) {
+				// Parse multiple file headers
+				if headers, ok := form.File[fileKey]; ok {
+					v.Field(i).Set(reflect.ValueOf(headers))
+				}
 			}
 		}
 	}
@@ -53,10 +53,13 @@ func main() {
 	// Initialize dependencies
 	platform := initPlatform(cfg, logger)
 	repository := initRepository(platform.MySql)
+	declareGradingQueues(logger, platform, repository)
 	publisher := initPublisher(cfg, platform)
 	usecase := initUsecase(cfg, logger, platform, repository, publisher)
 
 	startConsumer(logger, platform, usecase)
+	outboxRelay := startOutboxRelay(logger, repository.Grading, publisher.Grading)
+	submissionReaper := startSubmissionReaper(cfg, logger, platform, usecase)
 
 	// Initialize server with gracefully shutdown
 	signals := make(chan os.Signal, 1)
@@ -74,6 +77,8 @@ func main() {
 	logger.Info("Running cleanup tasks")
 
 	// Clean up
+	submissionReaper.Close()
+	outboxRelay.Close()
 	platform.RabbitMq.Close()
 	platform.SeaweedFs.Close()
 	platform.MySql.Close()
@@ -117,7 +122,7 @@ func initPlatform(cfg *config.Config, logger *zap.Logger) *domain.Platform {
 	logger.Info("Connected to SeaweedFs", zap.String("connection_time", time.Since(start).String()))
 
 	start = time.Now()
-	rabbitmq, err := platform.NewRabbitMq(cfg.Client.RabbitMq.Url)
+	rabbitmq, err := platform.NewRabbitMq(cfg.Client.RabbitMq.Url, logger)
 	if err != nil {
 		logger.Fatal("Cannot open RabbitMq connection", zap.Error(err))
 	}
@@ -142,10 +147,41 @@ func initRepository(mysql *platform.MySql) *domain.Repository {
 		Workspace:  repository.NewWorkspaceRepository(mysql),
 		Assignment: repository.NewAssignmentRepository(mysql),
 		Survey:     repository.NewSurveyRepository(mysql),
+		Grading:    repository.NewGradingRepository(mysql),
+		Language:   repository.NewLanguageRepository(mysql),
 		Misc:       repository.NewMiscRepsitory(mysql),
 	}
 }
 
+// declareGradingQueues declares a request queue for every routing key of languages,
+// languages graded by the same grader pool share a routing key
+func declareGradingQueues(
+	logger *zap.Logger,
+	platform *domain.Platform,
+	repository *domain.Repository,
+) {
+	languages, err := repository.Language.List()
+	if err != nil {
+		logger.Fatal("Cannot list languages to declare grading queues", zap.Error(err))
+	}
+
+	// The default routing key grades a submission whose language is removed
+	routingKeys := []string{constant.GradingRequestRoutingKey}
+	isDeclared := map[string]bool{constant.GradingRequestRoutingKey: true}
+	for _, language := range languages {
+		if !isDeclared[language.RoutingKey] {
+			isDeclared[language.RoutingKey] = true
+			routingKeys = append(routingKeys, language.RoutingKey)
+		}
+	}
+
+	for _, routingKey := range routingKeys {
+		if err := platform.RabbitMq.DeclareGradingQueue(routingKey); err != nil {
+			logger.Fatal("Cannot declare grading queue", zap.String("routing_key", routingKey), zap.Error(err))
+		}
+	}
+}
+
 func initUsecase(
 	cfg *config.Config,
 	logger *zap.Logger,
@@ -159,8 +195,12 @@ func initUsecase(
 	userUsecase := usecase.NewUserUsecase(platform.SeaweedFs, repository.User, sessionUsecase)
 	authUsecase := usecase.NewAuthUsecase(googleUsecase, sessionUsecase, userUsecase)
 	workspaceUsecase := usecase.NewWorkspaceUsecase(platform.SeaweedFs, repository.Workspace, repository.User, userUsecase)
-	assignmentUsecase := usecase.NewAssignmentUsecase(platform.SeaweedFs, repository.Assignment, publisher.Grading, workspaceUsecase)
+	languageUsecase := usecase.NewLanguageUsecase(repository.Language, workspaceUsecase)
+	assignmentUsecase := usecase.NewAssignmentUsecase(
+		cfg, logger, platform.SeaweedFs, repository.Assignment, repository.Grading, publisher.Grading, workspaceUsecase, languageUsecase,
+	)
 	surveyUsecase := usecase.NewSurveyUsecase(repository.Survey)
+	gradingUsecase := usecase.NewGradingUsecase(platform.RabbitMq, repository.Grading)
 
 	return &domain.Usecase{
 		Google:     googleUsecase,
@@ -170,6 +210,8 @@ func initUsecase(
 		Workspace:  workspaceUsecase,
 		Assignment: assignmentUsecase,
 		Survey:     surveyUsecase,
+		Grading:    gradingUsecase,
+		Language:   languageUsecase,
 		Misc:       miscUsecase,
 	}
 }
@@ -194,7 +236,29 @@ func startConsumer(
 		platform.WebSocketHub,
 		platform.InfluxDb,
 		usecase.Assignment,
+		usecase.Grading,
 	); err != nil {
 		logger.Fatal("Cannot start grading consumer", zap.Error(err))
 	}
 }
+
+func startOutboxRelay(
+	logger *zap.Logger,
+	gradingRepository domain.GradingRepository,
+	gradingPublisher domain.GradingPublisher,
+) *publisher.OutboxRelay {
+	relay := publisher.NewOutboxRelay(logger, gradingRepository, gradingPublisher)
+	relay.Start()
+	return relay
+}
+
+func startSubmissionReaper(
+	cfg *config.Config,
+	logger *zap.Logger,
+	platform *domain.Platform,
+	usecase *domain.Usecase,
+) *publisher.SubmissionReaper {
+	reaper := publisher.NewSubmissionReaper(cfg, logger, platform.WebSocketHub, usecase.Assignment)
+	reaper.Start()
+	return reaper
+}
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `grading_outbox` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `submission_id` BIGINT UNSIGNED NOT NULL,
+  `exchange` VARCHAR(64) NOT NULL,
+  `routing_key` VARCHAR(64) NOT NULL,
+  `body` LONGBLOB NOT NULL,
+  `attempt` INT NOT NULL DEFAULT 0,
+  `last_error` TEXT,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `next_attempt_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `sent_at` DATETIME NULL,
+  INDEX `grading_outbox_pending_idx` (`sent_at`, `next_attempt_at`),
+  FOREIGN KEY (`submission_id`) REFERENCES `submission`(`id`) ON DELETE CASCADE
+);
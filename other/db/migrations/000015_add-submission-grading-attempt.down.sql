@@ -0,0 +1,3 @@
+ALTER TABLE `submission`
+DROP `grading_attempt`,
+DROP `grading_requested_at`;
@@ -0,0 +1,5 @@
+ALTER TABLE `submission`
+ADD `grading_attempt` INT NOT NULL DEFAULT 1 AFTER `status`,
+ADD `grading_requested_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER `grading_attempt`;
+
+UPDATE `submission` SET grading_requested_at = submitted_at;
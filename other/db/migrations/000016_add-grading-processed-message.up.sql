@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS `grading_processed_message` (
+  `submission_id` BIGINT UNSIGNED NOT NULL,
+  `attempt` INT NOT NULL,
+  `processed_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`submission_id`, `attempt`),
+  FOREIGN KEY (`submission_id`) REFERENCES `submission`(`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `grading_dead_letter` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `queue` VARCHAR(64) NOT NULL,
+  `reason` VARCHAR(64) NOT NULL,
+  `body` LONGBLOB NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `replayed_at` DATETIME NULL
+);
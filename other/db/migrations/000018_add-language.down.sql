@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `workspace_language`;
+DROP TABLE IF EXISTS `language`;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS `language` (
+  `id` VARCHAR(32) PRIMARY KEY,
+  `name` VARCHAR(64) NOT NULL,
+  `compiler_version` VARCHAR(64) NOT NULL,
+  `file_extension` VARCHAR(16) NOT NULL,
+  `routing_key` VARCHAR(64) NOT NULL,
+  `is_enabled` BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS `workspace_language` (
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `language_id` VARCHAR(32) NOT NULL,
+  `is_enabled` BOOLEAN NOT NULL,
+  PRIMARY KEY (`workspace_id`, `language_id`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`),
+  FOREIGN KEY (`language_id`) REFERENCES `language`(`id`)
+);
+
+-- Seeding
+
+INSERT INTO `language` VALUES
+  ('c', 'C', 'GCC 12.2', 'c', 'request.c', TRUE),
+  ('cpp', 'C++', 'G++ 12.2', 'cpp', 'request.cpp', TRUE),
+  ('python', 'Python', 'Python 3.11', 'py', 'request.python', TRUE);
@@ -0,0 +1,2 @@
+ALTER TABLE `grading_outbox` DROP COLUMN `priority`;
+ALTER TABLE `assignment` DROP COLUMN `grading_priority`;
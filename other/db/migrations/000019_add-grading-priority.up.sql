@@ -0,0 +1,2 @@
+ALTER TABLE `assignment` ADD COLUMN `grading_priority` VARCHAR(32) NOT NULL DEFAULT 'NORMAL';
+ALTER TABLE `grading_outbox` ADD COLUMN `priority` TINYINT UNSIGNED NOT NULL DEFAULT 0;
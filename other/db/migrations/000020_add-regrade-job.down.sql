@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `regrade_job_submission`;
+DROP TABLE IF EXISTS `regrade_job`;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `regrade_job` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `assignment_id` BIGINT UNSIGNED NOT NULL,
+  `requester_id` VARCHAR(64) NOT NULL,
+  `testcase_revision` INT NOT NULL,
+  `total` INT NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (`assignment_id`) REFERENCES `assignment`(`id`),
+  FOREIGN KEY (`requester_id`) REFERENCES `user`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `regrade_job_submission` (
+  `job_id` BIGINT UNSIGNED NOT NULL,
+  `submission_id` BIGINT UNSIGNED NOT NULL,
+  PRIMARY KEY (`job_id`, `submission_id`),
+  FOREIGN KEY (`job_id`) REFERENCES `regrade_job`(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`submission_id`) REFERENCES `submission`(`id`) ON DELETE CASCADE
+);
@@ -0,0 +1 @@
+ALTER TABLE `submission` DROP COLUMN `testcase_revision`;
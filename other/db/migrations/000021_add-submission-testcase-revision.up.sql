@@ -0,0 +1 @@
+ALTER TABLE `submission` ADD COLUMN `testcase_revision` INT NULL;
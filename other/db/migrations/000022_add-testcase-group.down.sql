@@ -0,0 +1,3 @@
+ALTER TABLE `testcase`
+  DROP COLUMN `testcase_group`,
+  DROP COLUMN `weight`;
@@ -0,0 +1,3 @@
+ALTER TABLE `testcase`
+  ADD COLUMN `testcase_group` INT NULL,
+  ADD COLUMN `weight` DOUBLE NOT NULL DEFAULT 1;
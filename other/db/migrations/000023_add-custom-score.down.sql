@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `workspace_level`;
+ALTER TABLE `assignment` DROP COLUMN `max_score`;
@@ -0,0 +1,9 @@
+ALTER TABLE `assignment` ADD COLUMN `max_score` DOUBLE NULL;
+
+CREATE TABLE IF NOT EXISTS `workspace_level` (
+  `workspace_id` BIGINT UNSIGNED NOT NULL,
+  `level` VARCHAR(32) NOT NULL,
+  `score` DOUBLE NOT NULL,
+  PRIMARY KEY (`workspace_id`, `level`),
+  FOREIGN KEY (`workspace_id`) REFERENCES `workspace`(`id`)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE `submission` DROP COLUMN `raw_score`;
+
+ALTER TABLE `assignment`
+  DROP COLUMN `late_policy`,
+  DROP COLUMN `late_penalty`,
+  DROP COLUMN `late_penalty_interval`,
+  DROP COLUMN `late_grace_period`;
@@ -0,0 +1,13 @@
+-- Existing assignments accepted late submissions without a penalty, so they keep no policy
+-- to not change the score of their late submissions, new assignments are cut off by default
+ALTER TABLE `assignment`
+  ADD COLUMN `late_policy` VARCHAR(16) NOT NULL DEFAULT 'NONE',
+  ADD COLUMN `late_penalty` DOUBLE NOT NULL DEFAULT 0,
+  ADD COLUMN `late_penalty_interval` VARCHAR(8) NOT NULL DEFAULT 'DAY',
+  ADD COLUMN `late_grace_period` INT NOT NULL DEFAULT 0;
+
+ALTER TABLE `assignment` ALTER COLUMN `late_policy` SET DEFAULT 'CUTOFF';
+
+ALTER TABLE `submission` ADD COLUMN `raw_score` DOUBLE NOT NULL DEFAULT 0;
+
+UPDATE `submission` SET `raw_score` = `score`;
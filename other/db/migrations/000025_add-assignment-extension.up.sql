@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `assignment_extension` (
+  `assignment_id` BIGINT UNSIGNED NOT NULL,
+  `user_id` VARCHAR(64) NOT NULL,
+  `due_date` DATETIME NULL,
+  `time_limit_multiplier` DOUBLE NOT NULL DEFAULT 1,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`assignment_id`, `user_id`),
+  FOREIGN KEY (`assignment_id`) REFERENCES `assignment`(`id`),
+  FOREIGN KEY (`user_id`) REFERENCES `user`(`id`)
+);
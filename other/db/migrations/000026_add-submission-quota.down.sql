@@ -0,0 +1,3 @@
+ALTER TABLE `assignment`
+  DROP COLUMN `max_attempt`,
+  DROP COLUMN `submission_interval`;
@@ -0,0 +1,3 @@
+ALTER TABLE `assignment`
+  ADD COLUMN `max_attempt` INT NULL,
+  ADD COLUMN `submission_interval` INT NOT NULL DEFAULT 0;
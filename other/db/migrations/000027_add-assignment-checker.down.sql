@@ -0,0 +1,5 @@
+ALTER TABLE `assignment`
+  DROP COLUMN `checker_type`,
+  DROP COLUMN `checker_epsilon`,
+  DROP COLUMN `checker_language`,
+  DROP COLUMN `checker_file_url`;
@@ -0,0 +1,5 @@
+ALTER TABLE `assignment`
+  ADD COLUMN `checker_type` VARCHAR(16) NOT NULL DEFAULT 'EXACT',
+  ADD COLUMN `checker_epsilon` DOUBLE NULL,
+  ADD COLUMN `checker_language` VARCHAR(32) NULL,
+  ADD COLUMN `checker_file_url` VARCHAR(255) NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `submission_file`;
+
+ALTER TABLE `submission` DROP COLUMN `entry_point`;
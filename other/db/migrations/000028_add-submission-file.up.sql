@@ -0,0 +1,10 @@
+ALTER TABLE `submission` ADD COLUMN `entry_point` VARCHAR(255) NULL;
+
+CREATE TABLE IF NOT EXISTS `submission_file` (
+  `submission_id` BIGINT UNSIGNED NOT NULL,
+  `path` VARCHAR(255) NOT NULL,
+  `file_url` VARCHAR(512) NOT NULL,
+  `size` INT NOT NULL,
+  PRIMARY KEY (`submission_id`, `path`),
+  FOREIGN KEY (`submission_id`) REFERENCES `submission`(`id`)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `assignment_template` (
+  `assignment_id` BIGINT UNSIGNED NOT NULL,
+  `language` VARCHAR(32) NOT NULL,
+  `type` VARCHAR(16) NOT NULL,
+  `file_url` VARCHAR(255) NOT NULL,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`assignment_id`, `language`, `type`),
+  FOREIGN KEY (`assignment_id`) REFERENCES `assignment`(`id`),
+  FOREIGN KEY (`language`) REFERENCES `language`(`id`)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `testcase_generation` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `assignment_id` BIGINT UNSIGNED NOT NULL,
+  `revision` INT NOT NULL,
+  `language` VARCHAR(32) NOT NULL,
+  `status` VARCHAR(16) NOT NULL DEFAULT 'GENERATING',
+  `log` TEXT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE (`assignment_id`, `revision`),
+  FOREIGN KEY (`assignment_id`) REFERENCES `assignment`(`id`)
+);
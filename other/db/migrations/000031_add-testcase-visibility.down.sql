@@ -0,0 +1 @@
+ALTER TABLE `testcase` DROP COLUMN `visibility`;
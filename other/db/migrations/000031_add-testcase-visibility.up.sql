@@ -0,0 +1 @@
+ALTER TABLE `testcase` ADD COLUMN `visibility` VARCHAR(16) NOT NULL DEFAULT 'SAMPLE';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `similarity_pair`;
+DROP TABLE IF EXISTS `similarity_job`;
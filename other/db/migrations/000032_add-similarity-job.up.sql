@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS `similarity_job` (
+  `id` BIGINT UNSIGNED PRIMARY KEY,
+  `assignment_id` BIGINT UNSIGNED NOT NULL,
+  `requester_id` VARCHAR(64) NOT NULL,
+  `status` VARCHAR(16) NOT NULL DEFAULT 'RUNNING',
+  `total` INT NOT NULL,
+  `log` TEXT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  INDEX (`assignment_id`, `created_at`),
+  FOREIGN KEY (`assignment_id`) REFERENCES `assignment`(`id`),
+  FOREIGN KEY (`requester_id`) REFERENCES `user`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `similarity_pair` (
+  `job_id` BIGINT UNSIGNED NOT NULL,
+  `submission_id` BIGINT UNSIGNED NOT NULL,
+  `other_submission_id` BIGINT UNSIGNED NOT NULL,
+  `score` DOUBLE NOT NULL,
+  PRIMARY KEY (`job_id`, `submission_id`, `other_submission_id`),
+  INDEX (`job_id`, `score`),
+  FOREIGN KEY (`job_id`) REFERENCES `similarity_job`(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`submission_id`) REFERENCES `submission`(`id`) ON DELETE CASCADE,
+  FOREIGN KEY (`other_submission_id`) REFERENCES `submission`(`id`) ON DELETE CASCADE
+);
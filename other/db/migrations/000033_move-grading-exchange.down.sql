@@ -0,0 +1 @@
+UPDATE `grading_outbox` SET `exchange` = 'grading' WHERE `sent_at` IS NULL;
@@ -0,0 +1,2 @@
+-- Pending requests are published to the exchange of the API server instead of the grader
+UPDATE `grading_outbox` SET `exchange` = 'grading.v2' WHERE `sent_at` IS NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE `testcase_generation` DROP COLUMN `requested_at`, DROP COLUMN `attempt`;
+
+DELETE FROM `grading_outbox` WHERE `generation_id` IS NOT NULL;
+ALTER TABLE `grading_outbox` DROP FOREIGN KEY `grading_outbox_generation_fk`, DROP COLUMN `generation_id`;
+ALTER TABLE `grading_outbox` MODIFY `submission_id` BIGINT UNSIGNED NOT NULL;
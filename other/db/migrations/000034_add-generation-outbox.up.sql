@@ -0,0 +1,9 @@
+ALTER TABLE `grading_outbox` MODIFY `submission_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `grading_outbox`
+  ADD COLUMN `generation_id` BIGINT UNSIGNED NULL AFTER `submission_id`,
+  ADD CONSTRAINT `grading_outbox_generation_fk`
+    FOREIGN KEY (`generation_id`) REFERENCES `testcase_generation`(`id`) ON DELETE CASCADE;
+
+ALTER TABLE `testcase_generation`
+  ADD COLUMN `attempt` INT NOT NULL DEFAULT 1,
+  ADD COLUMN `requested_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP;
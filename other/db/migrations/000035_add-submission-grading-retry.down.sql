@@ -0,0 +1,2 @@
+ALTER TABLE `submission`
+DROP `grading_retry`;
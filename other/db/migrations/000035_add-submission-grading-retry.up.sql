@@ -0,0 +1,2 @@
+ALTER TABLE `submission`
+ADD `grading_retry` INT NOT NULL DEFAULT 1 AFTER `grading_attempt`;
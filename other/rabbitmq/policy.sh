@@ -0,0 +1,16 @@
+#!/bin/sh
+# Applies the dead-letter exchange of the API server to the grading queues owned by the grader.
+# A policy changes the queues in place, unlike declaring them again with different arguments
+# which is refused by RabbitMQ. Queues declared by the API server set their arguments directly.
+#
+# Usage: ./policy.sh [vhost]
+
+VHOST="${1:-/}"
+
+rabbitmqctl set_policy \
+  --vhost "$VHOST" \
+  --apply-to queues \
+  --priority 0 \
+  grading-dead-letter \
+  '^(grading|grading_response)$' \
+  '{"dead-letter-exchange":"grading.dlx"}'
@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/constant"
 	"github.com/codern-org/codern/platform"
 	payload "github.com/codern-org/codern/platform/amqp"
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -17,6 +19,7 @@ type gradingConsumer struct {
 	wsHub             *platform.WebSocketHub
 	influxDb          *platform.InfluxDb
 	assignmentUsecase domain.AssignmentUsecase
+	gradingUsecase    domain.GradingUsecase
 }
 
 func NewGradingConsumer(
@@ -25,6 +28,7 @@ func NewGradingConsumer(
 	wsHub *platform.WebSocketHub,
 	influxDb *platform.InfluxDb,
 	assignmentUsecase domain.AssignmentUsecase,
+	gradingUsecase domain.GradingUsecase,
 ) error {
 	consumer := &gradingConsumer{
 		logger:            logger,
@@ -32,17 +36,38 @@ func NewGradingConsumer(
 		wsHub:             wsHub,
 		influxDb:          influxDb,
 		assignmentUsecase: assignmentUsecase,
+		gradingUsecase:    gradingUsecase,
 	}
 	return consumer.startConsumers()
 }
 
 func (c *gradingConsumer) startConsumers() error {
-	if err := c.rabbitMq.Consume("grading_response", c.readSubmssionResult); err != nil {
+	if err := c.rabbitMq.Consume(constant.GradingResponseQueue, c.readSubmssionResult); err != nil {
+		return err
+	}
+	if err := c.rabbitMq.Consume(constant.GenerationResponseQueue, c.readGenerationResult); err != nil {
+		return err
+	}
+	if err := c.rabbitMq.Consume(constant.GradingDeadLetterQueue, c.readDeadLetter); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (c *gradingConsumer) readDeadLetter(delivery amqp.Delivery) {
+	queue, _ := delivery.Headers["x-first-death-queue"].(string)
+	reason, _ := delivery.Headers["x-first-death-reason"].(string)
+
+	if err := c.gradingUsecase.CreateDeadLetter(queue, reason, delivery.Body); err != nil {
+		delivery.Reject(true)
+		c.logger.Error("Cannot create dead letter", zap.String("queue", queue), zap.Error(err))
+		return
+	}
+
+	c.logger.Warn("Consumed dead letter", zap.String("queue", queue), zap.String("reason", reason))
+	delivery.Ack(false)
+}
+
 func (c *gradingConsumer) readSubmssionResult(delivery amqp.Delivery) {
 	var message payload.GradeResponseMessage
 
@@ -54,16 +79,36 @@ func (c *gradingConsumer) readSubmssionResult(delivery amqp.Delivery) {
 
 	assignmentId := message.Metadata.AssignmentId
 	submissionId := message.Metadata.SubmissionId
+	attempt := message.Metadata.Attempt
+	// Grading request published before the attempt is introduced
+	if attempt == 0 {
+		attempt = 1
+	}
 	results := make([]domain.SubmissionResult, 0)
 
-	assignment, err := c.assignmentUsecase.Get(assignmentId)
+	// Score against the testcases the submission is graded with
+	var assignment *domain.Assignment
+	var err error
+	if message.Metadata.Revision == 0 {
+		assignment, err = c.assignmentUsecase.Get(assignmentId)
+	} else {
+		assignment, err = c.assignmentUsecase.GetByRevision(assignmentId, message.Metadata.Revision)
+	}
 	if err != nil {
 		delivery.Reject(false)
 		c.logger.Error("Cannot get assignment when consuming submission result", zap.Error(err))
 		return
+	} else if assignment == nil {
+		// The assignment or the revision is deleted while grading
+		delivery.Reject(false)
+		c.logger.Warn("Assignment of submission result not found", zap.Int("assignment_id", assignmentId), zap.Int("submission_id", submissionId))
+		return
 	}
 
 	for i := range message.Results {
+		if i >= len(message.Metadata.TestcaseIds) {
+			break
+		}
 		results = append(results, domain.SubmissionResult{
 			SubmissionId: submissionId,
 			TestcaseId:   message.Metadata.TestcaseIds[i],
@@ -77,9 +122,14 @@ func (c *gradingConsumer) readSubmssionResult(delivery amqp.Delivery) {
 	if err := c.assignmentUsecase.CreateSubmissionResults(
 		assignment,
 		submissionId,
+		attempt,
 		message.CompileOutput,
 		results,
-	); err != nil {
+	); errs.HasCode(err, errs.ErrDupSubmissionResult) {
+		delivery.Ack(false)
+		c.logger.Info("Skipped duplicated submission result", zap.Int("submission_id", submissionId), zap.Int("attempt", attempt))
+		return
+	} else if err != nil {
 		delivery.Reject(true)
 		c.logger.Error("Cannot create submission results", zap.Error(err))
 		return
@@ -104,7 +154,15 @@ func (c *gradingConsumer) readSubmssionResult(delivery amqp.Delivery) {
 		},
 	)
 
-	if err := c.wsHub.SendMessage(submission.SubmitterId, "onSubmissionUpdate", submission); err != nil {
+	// The submitter receives only the results visible to them
+	submissions := []domain.Submission{*submission}
+	if err := c.assignmentUsecase.HideSubmissionResults(submission.SubmitterId, assignmentId, submissions); err != nil {
+		delivery.Reject(false)
+		c.logger.Error("Cannot hide submission results after consuming submission result", zap.Error(err))
+		return
+	}
+
+	if err := c.wsHub.SendMessage(submission.SubmitterId, "onSubmissionUpdate", &submissions[0]); err != nil {
 		delivery.Reject(false)
 		c.logger.Error("Cannot send websocket message after consuming submission result", zap.Error(err))
 		return
@@ -113,3 +171,43 @@ func (c *gradingConsumer) readSubmssionResult(delivery amqp.Delivery) {
 	c.logger.Info("Consumed submission result", zap.Int("submission_id", submissionId))
 	delivery.Ack(true)
 }
+
+func (c *gradingConsumer) readGenerationResult(delivery amqp.Delivery) {
+	var message payload.GenerateResponseMessage
+
+	if err := json.Unmarshal(delivery.Body, &message); err != nil {
+		delivery.Reject(false)
+		c.logger.Error("Cannot unmarshal GenerateResponseMessage", zap.Error(err))
+		return
+	}
+
+	generationId := message.Metadata.GenerationId
+	outputs := make([]domain.TestcaseOutput, 0, len(message.Results))
+	for i := range message.Results {
+		if i >= len(message.Metadata.TestcaseIds) {
+			break
+		}
+		outputs = append(outputs, domain.TestcaseOutput{
+			TestcaseId: message.Metadata.TestcaseIds[i],
+			IsPassed:   message.Results[i].Pass,
+			Output:     message.Results[i].Output,
+		})
+	}
+
+	if err := c.assignmentUsecase.CreateTestcaseGenerationResults(
+		generationId,
+		message.CompileOutput,
+		outputs,
+	); errs.HasCode(err, errs.ErrDupTestcaseGeneration) {
+		delivery.Ack(false)
+		c.logger.Info("Skipped duplicated testcase generation result", zap.Int("generation_id", generationId))
+		return
+	} else if err != nil {
+		delivery.Reject(true)
+		c.logger.Error("Cannot create testcase generation results", zap.Error(err))
+		return
+	}
+
+	c.logger.Info("Consumed testcase generation result", zap.Int("generation_id", generationId))
+	delivery.Ack(false)
+}
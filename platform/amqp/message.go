@@ -3,17 +3,37 @@ package payload
 import "time"
 
 type GradeRequestMessage struct {
-	Language  string               `json:"language"`
-	SourceUrl string               `json:"sourceUrl"`
-	Settings  GradeSettingsMessage `json:"settings"`
-	Test      []GradeTestMessage   `json:"test"`
-	Metadata  GradeMetadataMessage `json:"metadata"`
+	Language  string                `json:"language"`
+	SourceUrl string                `json:"sourceUrl"`
+	Manifest  *GradeManifestMessage `json:"manifest,omitempty"`
+	Settings  GradeSettingsMessage  `json:"settings"`
+	Test      []GradeTestMessage    `json:"test"`
+	Metadata  GradeMetadataMessage  `json:"metadata"`
+}
+
+// GradeManifestMessage lists the files of a multi-file submission
+type GradeManifestMessage struct {
+	EntryPoint string             `json:"entryPoint"`
+	Files      []GradeFileMessage `json:"files"`
+}
+
+type GradeFileMessage struct {
+	Path string `json:"path"`
+	Url  string `json:"url"`
 }
 
 type GradeSettingsMessage struct {
-	TimeLimit         int  `json:"timeLimit"`
-	MemoryLimit       int  `json:"memoryLimit"`
-	IsAutoTrimEnabled bool `json:"isAutoTrimEnabled"`
+	TimeLimit         int                 `json:"timeLimit"`
+	MemoryLimit       int                 `json:"memoryLimit"`
+	IsAutoTrimEnabled bool                `json:"isAutoTrimEnabled"`
+	Checker           GradeCheckerMessage `json:"checker"`
+}
+
+type GradeCheckerMessage struct {
+	Type      string   `json:"type"`
+	Epsilon   *float64 `json:"epsilon,omitempty"`
+	Language  *string  `json:"language,omitempty"`
+	SourceUrl string   `json:"sourceUrl,omitempty"`
 }
 
 type GradeTestMessage struct {
@@ -24,6 +44,8 @@ type GradeTestMessage struct {
 type GradeMetadataMessage struct {
 	AssignmentId int       `json:"assignmentId"`
 	SubmissionId int       `json:"submissionId"`
+	Attempt      int       `json:"attempt"`
+	Revision     int       `json:"revision"`
 	TestcaseIds  []int     `json:"testcaseIds"`
 	StartTime    time.Time `json:"startTime"`
 }
@@ -41,3 +63,32 @@ type GradeResponseResultMessage struct {
 	Time   int    `json:"time"`
 	Memory int    `json:"memory"`
 }
+
+// GenerateRequestMessage asks the grader to run a reference solution on the inputs of testcases
+type GenerateRequestMessage struct {
+	Language  string                  `json:"language"`
+	SourceUrl string                  `json:"sourceUrl"`
+	Settings  GradeSettingsMessage    `json:"settings"`
+	Inputs    []string                `json:"inputs"`
+	Metadata  GenerateMetadataMessage `json:"metadata"`
+}
+
+type GenerateMetadataMessage struct {
+	GenerationId int       `json:"generationId"`
+	AssignmentId int       `json:"assignmentId"`
+	Revision     int       `json:"revision"`
+	TestcaseIds  []int     `json:"testcaseIds"`
+	StartTime    time.Time `json:"startTime"`
+}
+
+type GenerateResponseMessage struct {
+	CompileOutput string                          `json:"compileOutput"`
+	Status        string                          `json:"status"`
+	Results       []GenerateResponseResultMessage `json:"results"`
+	Metadata      GenerateMetadataMessage         `json:"metadata"`
+}
+
+type GenerateResponseResultMessage struct {
+	Pass   bool   `json:"pass"`
+	Output string `json:"output"`
+}
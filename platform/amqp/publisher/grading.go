@@ -8,6 +8,8 @@ import (
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
 	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/generator"
 	"github.com/codern-org/codern/platform"
 	payload "github.com/codern-org/codern/platform/amqp"
 )
@@ -24,7 +26,23 @@ func NewGradingPublisher(cfg *config.Config, rabbitmq *platform.RabbitMq) domain
 	}
 }
 
-func (p *gradingPublisher) Grade(assignment *domain.AssignmentWithStatus, submission *domain.Submission) error {
+func (p *gradingPublisher) Grade(
+	assignment *domain.AssignmentWithStatus,
+	submission *domain.Submission,
+	language *domain.Language,
+) error {
+	outbox, err := p.CreateOutbox(assignment, submission, language)
+	if err != nil {
+		return err
+	}
+	return p.PublishOutbox(outbox)
+}
+
+func (p *gradingPublisher) CreateOutbox(
+	assignment *domain.AssignmentWithStatus,
+	submission *domain.Submission,
+	language *domain.Language,
+) (*domain.GradingOutbox, error) {
 	testcaseIds := make([]int, 0)
 	testcases := make([]payload.GradeTestMessage, 0)
 
@@ -34,14 +52,14 @@ func (p *gradingPublisher) Grade(assignment *domain.AssignmentWithStatus, submis
 			assignment.Testcases[i].InputFileUrl,
 		)
 		if err != nil {
-			return errs.New(errs.ErrCreateUrlPath, "invalid testcase input url", err)
+			return nil, errs.New(errs.ErrCreateUrlPath, "invalid testcase input url", err)
 		}
 		outputUrl, err := url.JoinPath(
 			p.cfg.Client.SeaweedFs.FilerUrls.External,
 			assignment.Testcases[i].OutputFileUrl,
 		)
 		if err != nil {
-			return errs.New(errs.ErrCreateUrlPath, "invalid testcase output url", err)
+			return nil, errs.New(errs.ErrCreateUrlPath, "invalid testcase output url", err)
 		}
 
 		testcases = append(testcases, payload.GradeTestMessage{
@@ -53,33 +71,139 @@ func (p *gradingPublisher) Grade(assignment *domain.AssignmentWithStatus, submis
 
 	sourceUrl, err := url.JoinPath(p.cfg.Client.SeaweedFs.FilerUrls.External, submission.FileUrl)
 	if err != nil {
-		return errs.New(errs.ErrCreateUrlPath, "invalid submission url", err)
+		return nil, errs.New(errs.ErrCreateUrlPath, "invalid submission url", err)
+	}
+
+	var manifest *payload.GradeManifestMessage
+	if submission.EntryPoint != nil {
+		manifest = &payload.GradeManifestMessage{
+			EntryPoint: *submission.EntryPoint,
+			Files:      make([]payload.GradeFileMessage, 0, len(submission.Files)),
+		}
+		for i := range submission.Files {
+			fileUrl, err := url.JoinPath(p.cfg.Client.SeaweedFs.FilerUrls.External, submission.Files[i].FileUrl)
+			if err != nil {
+				return nil, errs.New(errs.ErrCreateUrlPath, "invalid submission file url", err)
+			}
+			manifest.Files = append(manifest.Files, payload.GradeFileMessage{
+				Path: submission.Files[i].Path,
+				Url:  fileUrl,
+			})
+		}
+	}
+
+	checker := payload.GradeCheckerMessage{
+		Type:     string(assignment.CheckerType),
+		Epsilon:  assignment.CheckerEpsilon,
+		Language: assignment.CheckerLanguage,
+	}
+	if assignment.CheckerFileUrl != nil {
+		checkerUrl, err := url.JoinPath(p.cfg.Client.SeaweedFs.FilerUrls.External, *assignment.CheckerFileUrl)
+		if err != nil {
+			return nil, errs.New(errs.ErrCreateUrlPath, "invalid checker url", err)
+		}
+		checker.SourceUrl = checkerUrl
 	}
 
 	message := &payload.GradeRequestMessage{
 		Language:  submission.Language,
 		SourceUrl: sourceUrl,
+		Manifest:  manifest,
 		Test:      testcases,
 		Settings: payload.GradeSettingsMessage{
 			TimeLimit:         assignment.TimeLimit,
 			MemoryLimit:       assignment.MemoryLimit,
 			IsAutoTrimEnabled: assignment.IsAutoTrimEnabled,
+			Checker:           checker,
 		},
 		Metadata: payload.GradeMetadataMessage{
 			AssignmentId: assignment.Id,
 			SubmissionId: submission.Id,
+			Attempt:      submission.GradingAttempt,
+			Revision:     assignment.Testcases[0].Revision,
 			TestcaseIds:  testcaseIds,
 			StartTime:    time.Now(),
 		},
 	}
 	body, err := json.Marshal(message)
 	if err != nil {
-		return errs.New(errs.ErrGradingRequest, "cannot marshal grading request message", err)
+		return nil, errs.New(errs.ErrGradingRequest, "cannot marshal grading request message", err)
 	}
 
-	if err := p.rabbitMq.Publish("grading", "request", body); err != nil {
-		return errs.New(errs.ErrGradingRequest, "cannot publish grading request message", err)
+	routingKey := constant.GradingRequestRoutingKey
+	if language != nil {
+		routingKey = language.RoutingKey
 	}
 
+	return &domain.GradingOutbox{
+		Id:           generator.GetId(),
+		SubmissionId: &submission.Id,
+		Exchange:     constant.GradingExchange,
+		RoutingKey:   routingKey,
+		Body:         body,
+		Priority:     domain.GradingPriorityMap[assignment.GradingPriority],
+	}, nil
+}
+
+func (p *gradingPublisher) PublishOutbox(outbox *domain.GradingOutbox) error {
+	if err := p.rabbitMq.Publish(outbox.Exchange, outbox.RoutingKey, outbox.Priority, outbox.Body); err != nil {
+		return errs.New(errs.ErrGradingRequest, "cannot publish grading request message", err)
+	}
 	return nil
 }
+
+func (p *gradingPublisher) CreateGenerationOutbox(
+	generation *domain.TestcaseGeneration,
+	assignment *domain.Assignment,
+	testcases []domain.Testcase,
+	solution *domain.AssignmentTemplate,
+	language *domain.Language,
+) (*domain.GradingOutbox, error) {
+	testcaseIds := make([]int, 0, len(testcases))
+	inputs := make([]string, 0, len(testcases))
+	for i := range testcases {
+		inputUrl, err := url.JoinPath(p.cfg.Client.SeaweedFs.FilerUrls.External, testcases[i].InputFileUrl)
+		if err != nil {
+			return nil, errs.New(errs.ErrCreateUrlPath, "invalid testcase input url", err)
+		}
+		inputs = append(inputs, inputUrl)
+		testcaseIds = append(testcaseIds, testcases[i].Id)
+	}
+
+	sourceUrl, err := url.JoinPath(p.cfg.Client.SeaweedFs.FilerUrls.External, solution.FileUrl)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateUrlPath, "invalid reference solution url", err)
+	}
+
+	message := &payload.GenerateRequestMessage{
+		Language:  language.Id,
+		SourceUrl: sourceUrl,
+		Inputs:    inputs,
+		Settings: payload.GradeSettingsMessage{
+			TimeLimit:         assignment.TimeLimit,
+			MemoryLimit:       assignment.MemoryLimit,
+			IsAutoTrimEnabled: assignment.IsAutoTrimEnabled,
+		},
+		Metadata: payload.GenerateMetadataMessage{
+			GenerationId: generation.Id,
+			AssignmentId: assignment.Id,
+			Revision:     generation.Revision,
+			TestcaseIds:  testcaseIds,
+			StartTime:    time.Now(),
+		},
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, errs.New(errs.ErrGradingRequest, "cannot marshal generation request message", err)
+	}
+
+	// Generations have their own queue to not be consumed as grading requests
+	return &domain.GradingOutbox{
+		Id:           generator.GetId(),
+		GenerationId: &generation.Id,
+		Exchange:     constant.GradingExchange,
+		RoutingKey:   constant.GenerationRoutingKey,
+		Body:         body,
+		Priority:     domain.GradingPriorityMap[domain.GradingPriorityHigh],
+	}, nil
+}
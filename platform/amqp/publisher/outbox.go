@@ -0,0 +1,82 @@
+package publisher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/constant"
+	"go.uber.org/zap"
+)
+
+// OutboxRelay periodically publishes grading requests persisted in the outbox
+// so that no accepted submission is lost when the broker is unavailable
+type OutboxRelay struct {
+	logger            *zap.Logger
+	gradingRepository domain.GradingRepository
+	gradingPublisher  domain.GradingPublisher
+	done              chan struct{}
+	wg                sync.WaitGroup
+}
+
+func NewOutboxRelay(
+	logger *zap.Logger,
+	gradingRepository domain.GradingRepository,
+	gradingPublisher domain.GradingPublisher,
+) *OutboxRelay {
+	return &OutboxRelay{
+		logger:            logger,
+		gradingRepository: gradingRepository,
+		gradingPublisher:  gradingPublisher,
+		done:              make(chan struct{}),
+	}
+}
+
+func (r *OutboxRelay) Start() {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(constant.GradingOutboxInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				r.relay()
+			}
+		}
+	}()
+}
+
+func (r *OutboxRelay) Close() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func (r *OutboxRelay) relay() {
+	err := r.gradingRepository.ProcessOutbox(
+		constant.GradingOutboxBatchSize,
+		constant.GradingOutboxMaxBackoff,
+		func(outbox *domain.GradingOutbox) error {
+			if err := r.gradingPublisher.PublishOutbox(outbox); err != nil {
+				r.logger.Warn(
+					"Cannot relay grading outbox",
+					zap.Int("outbox_id", outbox.Id),
+					zap.Intp("submission_id", outbox.SubmissionId),
+					zap.Intp("generation_id", outbox.GenerationId),
+					zap.Int("attempt", outbox.Attempt+1),
+					zap.Error(err),
+				)
+				return err
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		r.logger.Error("Cannot process grading outbox", zap.Error(err))
+	}
+}
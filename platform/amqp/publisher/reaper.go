@@ -0,0 +1,96 @@
+package publisher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/platform"
+	"go.uber.org/zap"
+)
+
+// SubmissionReaper periodically re-enqueues submissions stuck in grading status
+// and marks them as system failure once all attempts are exhausted,
+// testcase generations stuck in generating status are reaped the same way
+type SubmissionReaper struct {
+	logger            *zap.Logger
+	interval          time.Duration
+	deadline          time.Duration
+	maxAttempt        int
+	wsHub             *platform.WebSocketHub
+	assignmentUsecase domain.AssignmentUsecase
+	done              chan struct{}
+	wg                sync.WaitGroup
+}
+
+func NewSubmissionReaper(
+	cfg *config.Config,
+	logger *zap.Logger,
+	wsHub *platform.WebSocketHub,
+	assignmentUsecase domain.AssignmentUsecase,
+) *SubmissionReaper {
+	return &SubmissionReaper{
+		logger:            logger,
+		interval:          time.Duration(cfg.Grading.Reaper.Interval) * time.Second,
+		deadline:          time.Duration(cfg.Grading.Reaper.Deadline) * time.Second,
+		maxAttempt:        cfg.Grading.Reaper.MaxAttempt,
+		wsHub:             wsHub,
+		assignmentUsecase: assignmentUsecase,
+		done:              make(chan struct{}),
+	}
+}
+
+func (r *SubmissionReaper) Start() {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				r.reap()
+			}
+		}
+	}()
+}
+
+func (r *SubmissionReaper) Close() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+func (r *SubmissionReaper) reap() {
+	failedSubmissions, err := r.assignmentUsecase.ReapSubmissions(r.deadline, r.maxAttempt)
+	if err != nil {
+		r.logger.Error("Cannot reap stuck submissions", zap.Error(err))
+	}
+
+	for i := range failedSubmissions {
+		submissionId := failedSubmissions[i].Id
+		r.logger.Warn("Submission is marked as system failure", zap.Int("submission_id", submissionId))
+
+		submission, err := r.assignmentUsecase.GetSubmission(submissionId)
+		if err != nil || submission == nil {
+			r.logger.Error("Cannot get submission after marking as system failure", zap.Int("submission_id", submissionId), zap.Error(err))
+			continue
+		}
+
+		if err := r.wsHub.SendMessage(submission.SubmitterId, "onSubmissionUpdate", submission); err != nil {
+			r.logger.Warn("Cannot send websocket message after marking as system failure", zap.Int("submission_id", submissionId), zap.Error(err))
+		}
+	}
+	failedGenerations, err := r.assignmentUsecase.ReapTestcaseGenerations(r.deadline, r.maxAttempt)
+	if err != nil {
+		r.logger.Error("Cannot reap stuck testcase generations", zap.Error(err))
+	}
+	for i := range failedGenerations {
+		r.logger.Warn("Testcase generation is marked as failed", zap.Int("generation_id", failedGenerations[i].Id))
+	}
+}
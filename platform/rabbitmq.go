@@ -2,62 +2,294 @@ package platform
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"time"
 
+	"github.com/codern-org/codern/internal/constant"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
 )
 
+type rabbitMqConsumer struct {
+	queue string
+	fn    func(amqp.Delivery)
+}
+
 type RabbitMq struct {
-	conn       *amqp.Connection
-	ch         *amqp.Channel
-	consumerWg sync.WaitGroup
+	url         string
+	logger      *zap.Logger
+	mu          sync.RWMutex
+	conn        *amqp.Connection
+	ch          *amqp.Channel
+	isConnected bool
+	routingKeys []string
+	consumers   []rabbitMqConsumer
+	done        chan struct{}
+	consumerWg  sync.WaitGroup
 }
 
-func NewRabbitMq(url string) (*RabbitMq, error) {
-	conn, err := amqp.Dial(url)
-	if err != nil {
+func NewRabbitMq(url string, logger *zap.Logger) (*RabbitMq, error) {
+	rabbitMq := &RabbitMq{
+		url:    url,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+	if err := rabbitMq.connect(); err != nil {
 		return nil, err
 	}
 
+	go rabbitMq.watch()
+
+	return rabbitMq, nil
+}
+
+func (q *RabbitMq) connect() error {
+	conn, err := amqp.Dial(q.url)
+	if err != nil {
+		return err
+	}
+
 	ch, err := conn.Channel()
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return err
+	}
+
+	// Every publishing is acknowledged by the broker in confirm mode
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.conn = conn
+	q.ch = ch
+
+	if err := q.setup(); err != nil {
+		conn.Close()
+		return err
+	}
+
+	// Redeclare grading queues before consumers as a consumer might consume from them
+	for _, routingKey := range q.routingKeys {
+		if err := q.declareGradingQueue(routingKey); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	// Redeclare consumers registered before the connection is lost
+	for _, consumer := range q.consumers {
+		if err := q.consume(consumer); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	q.isConnected = true
+	return nil
+}
+
+// watch reconnects with an exponential backoff whenever the connection or the channel is closed
+func (q *RabbitMq) watch() {
+	for {
+		q.mu.RLock()
+		conn, ch := q.conn, q.ch
+		q.mu.RUnlock()
+
+		connClose := conn.NotifyClose(make(chan *amqp.Error, 1))
+		chClose := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+		var closeErr *amqp.Error
+		select {
+		case <-q.done:
+			return
+		case closeErr = <-connClose:
+		case closeErr = <-chClose:
+		}
+
+		q.mu.Lock()
+		q.isConnected = false
+		q.mu.Unlock()
+		conn.Close()
+
+		select {
+		case <-q.done:
+			return
+		default:
+		}
+		q.logger.Error("RabbitMq connection is lost", zap.Error(closeErr))
+
+		backoff := constant.RabbitMqReconnectMinBackoff
+		for {
+			select {
+			case <-q.done:
+				return
+			case <-time.After(backoff):
+			}
+
+			start := time.Now()
+			if err := q.connect(); err != nil {
+				q.logger.Warn("Cannot reconnect to RabbitMq", zap.Duration("backoff", backoff), zap.Error(err))
+				backoff = min(backoff*2, constant.RabbitMqReconnectMaxBackoff)
+				continue
+			}
+			q.logger.Info("Reconnected to RabbitMq", zap.String("connection_time", time.Since(start).String()))
+			break
+		}
+	}
+}
+
+// setup declares the part of the grading topology owned by the API server. The grading exchange
+// and queues are owned by the grader and never redeclared, as a declaration with different
+// arguments closes the channel, they are dead-lettered through the policy in other/rabbitmq
+func (q *RabbitMq) setup() error {
+	if err := q.ch.ExchangeDeclare(constant.GradingDeadLetterExchange, "fanout", true, false, false, false, nil); err != nil {
+		return err
+	}
+	if _, err := q.ch.QueueDeclare(constant.GradingDeadLetterQueue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	if err := q.ch.QueueBind(constant.GradingDeadLetterQueue, "", constant.GradingDeadLetterExchange, false, nil); err != nil {
+		return err
+	}
+
+	// Requests are routed by the exact routing key of a language to not deliver a request to
+	// more than one grader pool, the exchange replaces the grading exchange of the grader
+	if err := q.ch.ExchangeDeclare(constant.GradingExchange, "direct", true, false, false, false, nil); err != nil {
+		return err
+	}
+
+	deadLetterArgs := amqp.Table{"x-dead-letter-exchange": constant.GradingDeadLetterExchange}
+	if _, err := q.ch.QueueDeclare(constant.GenerationResponseQueue, true, false, false, false, deadLetterArgs); err != nil {
+		return err
+	}
+	// Generation requests of every language share a queue separated from grading requests
+	if err := q.declareGradingQueue(constant.GenerationRoutingKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeclareGradingQueue declares the request queue of a grader pool bound by its routing key,
+// the queue is redeclared after every reconnection
+func (q *RabbitMq) DeclareGradingQueue(routingKey string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.declareGradingQueue(routingKey); err != nil {
+		return err
+	}
+	q.routingKeys = append(q.routingKeys, routingKey)
+
+	return nil
+}
+
+func (q *RabbitMq) declareGradingQueue(routingKey string) error {
+	queue := GradingQueue(routingKey)
+	// The arguments of a queue cannot be changed once it is declared, see other/rabbitmq/README.md
+	args := amqp.Table{
+		"x-dead-letter-exchange": constant.GradingDeadLetterExchange,
+		"x-max-priority":         constant.GradingMaxPriority,
 	}
+	if _, err := q.ch.QueueDeclare(queue, true, false, false, false, args); err != nil {
+		return err
+	}
+	return q.ch.QueueBind(queue, routingKey, constant.GradingExchange, false, nil)
+}
 
-	return &RabbitMq{
-		conn: conn,
-		ch:   ch,
-	}, nil
+// GradingQueue names the request queue of a routing key, a grader pool consumes from it
+func GradingQueue(routingKey string) string {
+	return constant.GradingExchange + "." + routingKey
+}
+
+func (q *RabbitMq) IsConnected() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.isConnected
 }
 
 func (q *RabbitMq) Close() {
-	q.ch.Cancel("codern", false)
+	close(q.done)
+
+	q.mu.Lock()
+	for _, consumer := range q.consumers {
+		q.ch.Cancel(consumerTag(consumer.queue), false)
+	}
 	q.conn.Close()
+	q.isConnected = false
+	q.mu.Unlock()
+
 	q.consumerWg.Wait()
 }
 
-func (q *RabbitMq) Publish(exchange string, key string, body []byte) error {
-	return q.ch.PublishWithContext(context.Background(), exchange, key, false, false, amqp.Publishing{
+// Publish waits until the broker confirms the message, a nack or a timeout is returned as an error
+func (q *RabbitMq) Publish(exchange string, key string, priority uint8, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), constant.RabbitMqPublishConfirmTimeout)
+	defer cancel()
+
+	q.mu.RLock()
+	if !q.isConnected {
+		q.mu.RUnlock()
+		return errors.New("rabbitmq is not connected")
+	}
+	confirmation, err := q.ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, amqp.Publishing{
 		ContentType:  "application/json",
 		Body:         body,
 		DeliveryMode: amqp.Persistent,
+		Priority:     priority,
 	})
+	q.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	isAcked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot wait for publishing confirmation: %w", err)
+	} else if !isAcked {
+		return errors.New("publishing is not acknowledged by rabbitmq")
+	}
+	return nil
 }
 
+// Consume registers a consumer which is redeclared after every reconnection
 func (q *RabbitMq) Consume(queue string, fn func(amqp.Delivery)) error {
-	q.consumerWg.Add(1)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	consumer := rabbitMqConsumer{queue: queue, fn: fn}
+	if err := q.consume(consumer); err != nil {
+		return err
+	}
+	q.consumers = append(q.consumers, consumer)
 
-	messages, err := q.ch.Consume(queue, "codern", false, false, false, false, nil)
+	return nil
+}
+
+func (q *RabbitMq) consume(consumer rabbitMqConsumer) error {
+	messages, err := q.ch.Consume(consumer.queue, consumerTag(consumer.queue), false, false, false, false, nil)
 	if err != nil {
 		return err
 	}
+	q.consumerWg.Add(1)
 
 	go func() {
 		for delivery := range messages {
-			fn(delivery)
+			consumer.fn(delivery)
 		}
 		q.consumerWg.Done()
 	}()
 
 	return nil
 }
+
+// Consumer tag must be unique per channel
+func consumerTag(queue string) string {
+	return "codern." + queue
+}
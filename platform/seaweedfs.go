@@ -43,6 +43,15 @@ func (fs *SeaweedFs) Upload(content io.Reader, size int, path string) error {
 	return nil
 }
 
+// Download streams the content of a file to the callback
+func (fs *SeaweedFs) Download(path string, callback func(io.Reader) error) error {
+	filer := fs.client.Filers()[0]
+	if filer == nil {
+		return errors.New("cannot connect to file system upstream")
+	}
+	return filer.Download(path, nil, callback)
+}
+
 func (fs *SeaweedFs) Delete(path string, args url.Values) error {
 	filer := fs.client.Filers()[0]
 	if filer == nil {
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"bufio"
+	"fmt"
 	"time"
 
 	"github.com/codern-org/codern/domain"
@@ -33,12 +35,39 @@ func (c *AssignmentController) Create(ctx *fiber.Ctx) error {
 	if ok, err := c.validator.Validate(&pl, ctx); !ok {
 		return err
 	}
-	if err := payload.ValidateTestcaseFiles(pl.TestcaseInputFiles, pl.TestcaseOutputFiles); err != nil {
-		return err
+
+	var testcaseFiles []domain.TestcaseFile
+	if pl.TestcaseArchive != nil {
+		files, err := payload.GetTestcaseArchive(pl.TestcaseArchive, pl.SolutionLanguage)
+		if err != nil {
+			return err
+		}
+		testcaseFiles = files
+	} else {
+		if err := payload.ValidateTestcaseFiles(pl.TestcaseInputFiles, pl.TestcaseOutputFiles, pl.SolutionLanguage); err != nil {
+			return err
+		}
+		if err := payload.ValidateTestcaseGroups(
+			pl.TestcaseInputFiles, pl.TestcaseGroups, pl.TestcaseWeights, pl.TestcaseVisibilities,
+		); err != nil {
+			return err
+		}
+		testcaseFiles = domain.CreateTestcaseFiles(
+			pl.TestcaseInputFiles, pl.TestcaseOutputFiles, pl.TestcaseGroups, pl.TestcaseWeights, pl.TestcaseVisibilities,
+		)
 	}
 
 	user := middleware.GetUserFromCtx(ctx)
-	testcaseFiles := domain.CreateTestcaseFiles(pl.TestcaseInputFiles, pl.TestcaseOutputFiles)
+
+	var checkerFile *domain.File
+	if pl.CheckerFile != nil {
+		checkerFile = &domain.File{Reader: pl.CheckerFile}
+	}
+
+	var solutionFile *domain.File
+	if pl.SolutionFile != nil {
+		solutionFile = &domain.File{Reader: pl.SolutionFile}
+	}
 
 	fileMimeType, err := validator.GetMimeType(pl.DetailFile)
 	if err != nil {
@@ -52,18 +81,32 @@ func (c *AssignmentController) Create(ctx *fiber.Ctx) error {
 		user.Id,
 		pl.WorkspaceId,
 		&domain.CreateAssignment{
-			Name:        pl.Name,
-			Description: pl.Description,
-			MemoryLimit: pl.MemoryLimit,
-			TimeLimit:   pl.TimeLimit,
-			Level:       pl.Level,
-			PublishDate: pl.PublishDate,
-			DueDate:     pl.DueDate,
+			Name:                pl.Name,
+			Description:         pl.Description,
+			MemoryLimit:         pl.MemoryLimit,
+			TimeLimit:           pl.TimeLimit,
+			Level:               pl.Level,
+			MaxScore:            pl.MaxScore,
+			GradingPriority:     pl.GradingPriority,
+			PublishDate:         pl.PublishDate,
+			DueDate:             pl.DueDate,
+			LatePolicy:          pl.LatePolicy,
+			LatePenalty:         pl.LatePenalty,
+			LatePenaltyInterval: pl.LatePenaltyInterval,
+			LateGracePeriod:     pl.LateGracePeriod,
+			MaxAttempt:          pl.MaxAttempt,
+			SubmissionInterval:  pl.SubmissionInterval,
+			CheckerType:         pl.CheckerType,
+			CheckerEpsilon:      pl.CheckerEpsilon,
+			CheckerLanguage:     pl.CheckerLanguage,
+			CheckerFile:         checkerFile,
 			DetailFile: &domain.File{
 				Reader:   pl.DetailFile,
 				MimeType: fileMimeType,
 			},
-			TestcaseFiles: testcaseFiles,
+			TestcaseFiles:    testcaseFiles,
+			SolutionLanguage: pl.SolutionLanguage,
+			SolutionFile:     solutionFile,
 		},
 	); err != nil {
 		return err
@@ -93,20 +136,27 @@ func (c *AssignmentController) CreateSubmission(ctx *fiber.Ctx) error {
 		return err
 	}
 
+	source, err := payload.GetSubmissionSource(&pl)
+	if err != nil {
+		return err
+	}
+
 	user := middleware.GetUserFromCtx(ctx)
 
-	if err := c.assignmentUsecase.CreateSubmission(
+	isQueued, err := c.assignmentUsecase.CreateSubmission(
 		user.Id,
 		pl.AssignmentId,
 		pl.WorkspaceId,
 		pl.Language,
-		pl.SourceCode,
-	); err != nil {
+		source,
+	)
+	if err != nil {
 		return err
 	}
 
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
 		"submitted_at": time.Now(),
+		"is_queued":    isQueued,
 	})
 }
 
@@ -207,12 +257,39 @@ func (c *AssignmentController) Update(ctx *fiber.Ctx) error {
 	if ok, err := c.validator.Validate(&pl, ctx); !ok {
 		return err
 	}
-	if err := payload.ValidateTestcaseFiles(pl.TestcaseInputFiles, pl.TestcaseOutputFiles); err != nil {
-		return err
+
+	var testcaseFiles []domain.TestcaseFile
+	if pl.TestcaseArchive != nil {
+		files, err := payload.GetTestcaseArchive(pl.TestcaseArchive, pl.SolutionLanguage)
+		if err != nil {
+			return err
+		}
+		testcaseFiles = files
+	} else {
+		if err := payload.ValidateTestcaseFiles(pl.TestcaseInputFiles, pl.TestcaseOutputFiles, pl.SolutionLanguage); err != nil {
+			return err
+		}
+		if err := payload.ValidateTestcaseGroups(
+			pl.TestcaseInputFiles, pl.TestcaseGroups, pl.TestcaseWeights, pl.TestcaseVisibilities,
+		); err != nil {
+			return err
+		}
+		testcaseFiles = domain.CreateTestcaseFiles(
+			pl.TestcaseInputFiles, pl.TestcaseOutputFiles, pl.TestcaseGroups, pl.TestcaseWeights, pl.TestcaseVisibilities,
+		)
 	}
 
 	user := middleware.GetUserFromCtx(ctx)
-	testcaseFiles := domain.CreateTestcaseFiles(pl.TestcaseInputFiles, pl.TestcaseOutputFiles)
+
+	var checkerFile *domain.File
+	if pl.CheckerFile != nil {
+		checkerFile = &domain.File{Reader: pl.CheckerFile}
+	}
+
+	var solutionFile *domain.File
+	if pl.SolutionFile != nil {
+		solutionFile = &domain.File{Reader: pl.SolutionFile}
+	}
 
 	fileMimeType, err := validator.GetMimeType(pl.DetailFile)
 	if err != nil {
@@ -226,18 +303,32 @@ func (c *AssignmentController) Update(ctx *fiber.Ctx) error {
 		user.Id,
 		pl.AssignmentId,
 		&domain.UpdateAssignment{
-			Name:        pl.Name,
-			Description: pl.Description,
-			MemoryLimit: pl.MemoryLimit,
-			TimeLimit:   pl.TimeLimit,
-			Level:       pl.Level,
-			PublishDate: pl.PublishDate,
-			DueDate:     pl.DueDate,
+			Name:                pl.Name,
+			Description:         pl.Description,
+			MemoryLimit:         pl.MemoryLimit,
+			TimeLimit:           pl.TimeLimit,
+			Level:               pl.Level,
+			MaxScore:            pl.MaxScore,
+			GradingPriority:     pl.GradingPriority,
+			PublishDate:         pl.PublishDate,
+			DueDate:             pl.DueDate,
+			LatePolicy:          pl.LatePolicy,
+			LatePenalty:         pl.LatePenalty,
+			LatePenaltyInterval: pl.LatePenaltyInterval,
+			LateGracePeriod:     pl.LateGracePeriod,
+			MaxAttempt:          pl.MaxAttempt,
+			SubmissionInterval:  pl.SubmissionInterval,
+			CheckerType:         pl.CheckerType,
+			CheckerEpsilon:      pl.CheckerEpsilon,
+			CheckerLanguage:     pl.CheckerLanguage,
+			CheckerFile:         checkerFile,
 			DetailFile: &domain.File{
 				Reader:   pl.DetailFile,
 				MimeType: fileMimeType,
 			},
-			TestcaseFiles: &testcaseFiles,
+			TestcaseFiles:    &testcaseFiles,
+			SolutionLanguage: pl.SolutionLanguage,
+			SolutionFile:     solutionFile,
 		},
 	); err != nil {
 		return err
@@ -264,3 +355,536 @@ func (c *AssignmentController) Delete(ctx *fiber.Ctx) error {
 		"deleted_at": time.Now(),
 	})
 }
+
+// DiffSubmission godoc
+//
+// @Summary 		Diff two submissions
+// @Description	Get the unified diff between two submissions of the same user, a member can only diff their own
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				submissionId				path	int				true	"Submission ID"
+// @Param				otherSubmissionId		path	int				true	"Other submission ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/submissions/{submissionId}/diff/{otherSubmissionId} [get]
+func (c *AssignmentController) DiffSubmission(ctx *fiber.Ctx) error {
+	var pl payload.SubmissionDiffPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	diff, err := c.assignmentUsecase.DiffSubmission(user.Id, pl.AssignmentId, pl.SubmissionId, pl.OtherSubmissionId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, diff)
+}
+
+// Regrade godoc
+//
+// @Summary 		Regrade an assignment
+// @Description	Republish the submissions of an assignment against the current testcases as a regrade job
+// @Tags 				workspace
+// @Accept 			json
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				payload							body	payload.RegradePayload true "Payload"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/regrade [post]
+func (c *AssignmentController) Regrade(ctx *fiber.Ctx) error {
+	var pl payload.RegradePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	job, err := c.assignmentUsecase.Regrade(user.Id, pl.AssignmentId, &domain.RegradeFilter{
+		IsLatestOnly: pl.LatestOnly,
+		UserIds:      pl.UserIds,
+		Status:       pl.Status,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, job)
+}
+
+// GetRegradeJob godoc
+//
+// @Summary 		Get a regrade job
+// @Description	Get the progress of a regrade job
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				regradeJobId				path	int				true	"Regrade job ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/regrade/{regradeJobId} [get]
+func (c *AssignmentController) GetRegradeJob(ctx *fiber.Ctx) error {
+	var pl payload.RegradeJobPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	job, err := c.assignmentUsecase.GetRegradeJob(user.Id, pl.AssignmentId, pl.RegradeJobId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, job)
+}
+
+// DetectSimilarity godoc
+//
+// @Summary 		Detect similarity of submissions
+// @Description	Start a similarity job comparing the latest submissions of every user in background
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/similarity [post]
+func (c *AssignmentController) DetectSimilarity(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	job, err := c.assignmentUsecase.DetectSimilarity(user.Id, pl.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, job)
+}
+
+// GetSimilarityReport godoc
+//
+// @Summary 		Get a similarity report
+// @Description	Get a similarity job with its pairs of submissions ranked from the most similar
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				similarityJobId			path	int				true	"Similarity job ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/similarity/{similarityJobId} [get]
+func (c *AssignmentController) GetSimilarityReport(ctx *fiber.Ctx) error {
+	var pl payload.SimilarityJobPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	report, err := c.assignmentUsecase.GetSimilarityReport(user.Id, pl.AssignmentId, pl.SimilarityJobId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, report)
+}
+
+// CompareSimilarity godoc
+//
+// @Summary 		Compare a similar pair of submissions
+// @Description	Get the sources of a pair of submissions in a similarity report side by side
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				similarityJobId			path	int				true	"Similarity job ID"
+// @Param				submissionId				path	int				true	"Submission ID"
+// @Param				otherSubmissionId		path	int				true	"Other submission ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/similarity/{similarityJobId}/pairs/{submissionId}/{otherSubmissionId} [get]
+func (c *AssignmentController) CompareSimilarity(ctx *fiber.Ctx) error {
+	var pl payload.SimilarityPairPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	comparison, err := c.assignmentUsecase.CompareSimilarity(
+		user.Id, pl.AssignmentId, pl.SimilarityJobId, pl.SubmissionId, pl.OtherSubmissionId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, comparison)
+}
+
+// Export godoc
+//
+// @Summary 		Export an assignment
+// @Description	Stream the assignment, its detail, checker and latest testcases as a zip package
+// @Tags 				workspace
+// @Produce 		application/zip
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/export [get]
+func (c *AssignmentController) Export(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	export, err := c.assignmentUsecase.ExportAssignment(user.Id, pl.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"assignment-%d.zip\"", pl.AssignmentId))
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The response is already sent, a failure can only cut the package short
+		_ = export(w)
+		w.Flush()
+	})
+	return nil
+}
+
+// Import godoc
+//
+// @Summary 		Import an assignment
+// @Description	Create an assignment from an exported package, the dates can be replaced
+// @Tags 				workspace
+// @Accept 			mpfd
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				payload							body	payload.ImportAssignmentPayload true "Payload"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/import [post]
+func (c *AssignmentController) Import(ctx *fiber.Ctx) error {
+	var pl payload.ImportAssignmentPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	pkg, err := pl.Package.Open()
+	if err != nil {
+		return errs.New(errs.ErrBodyParser, "cannot parse the file", err)
+	}
+	defer pkg.Close()
+
+	if err := c.assignmentUsecase.ImportAssignment(
+		user.Id,
+		pl.WorkspaceId,
+		pkg,
+		pl.Package.Size,
+		&domain.AssignmentSchedule{
+			PublishDate: pl.PublishDate,
+			DueDate:     pl.DueDate,
+		},
+	); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"created_at": time.Now(),
+	})
+}
+
+// Copy godoc
+//
+// @Summary 		Copy an assignment to another workspace
+// @Description	Copy an assignment to another workspace owned by the user, the dates can be replaced
+// @Tags 				workspace
+// @Accept 			json
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				payload							body	payload.CopyAssignmentPayload true "Payload"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/copy [post]
+func (c *AssignmentController) Copy(ctx *fiber.Ctx) error {
+	var pl payload.CopyAssignmentPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.assignmentUsecase.CopyAssignment(
+		user.Id,
+		pl.AssignmentId,
+		pl.TargetWorkspaceId,
+		&domain.AssignmentSchedule{
+			PublishDate: pl.PublishDate,
+			DueDate:     pl.DueDate,
+		},
+	); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"created_at": time.Now(),
+	})
+}
+
+// GetTestcaseGeneration godoc
+//
+// @Summary 		Get the latest testcase generation of an assignment
+// @Description	Get the status of generating testcase outputs from the reference solution
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/testcase-generation [get]
+func (c *AssignmentController) GetTestcaseGeneration(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	generation, err := c.assignmentUsecase.GetLatestTestcaseGeneration(user.Id, pl.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, generation)
+}
+
+// ExportTestcases godoc
+//
+// @Summary 		Export testcases of an assignment
+// @Description	Stream the latest testcases as a zip archive of N.in, N.out and the testcase metadata
+// @Tags 				workspace
+// @Produce 		application/zip
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/testcases/export [get]
+func (c *AssignmentController) ExportTestcases(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	export, err := c.assignmentUsecase.ExportTestcases(user.Id, pl.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"testcases-%d.zip\"", pl.AssignmentId))
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The response is already sent, a failure can only cut the archive short
+		_ = export(w)
+		w.Flush()
+	})
+	return nil
+}
+
+// ListExtension godoc
+//
+// @Summary 		List extensions of an assignment
+// @Description	List the due date and time limit extensions granted to participants
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/extensions [get]
+func (c *AssignmentController) ListExtension(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	extensions, err := c.assignmentUsecase.ListExtension(user.Id, pl.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, extensions)
+}
+
+// UpdateExtension godoc
+//
+// @Summary 		Grant an extension to a participant
+// @Description	Create or replace the due date and time limit extension of a participant
+// @Tags 				workspace
+// @Accept 			json
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				userId							path	string		true	"User ID"
+// @Param				payload							body	payload.UpdateExtensionPayload true "Payload"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/extensions/{userId} [put]
+func (c *AssignmentController) UpdateExtension(ctx *fiber.Ctx) error {
+	var pl payload.UpdateExtensionPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	extension := &domain.AssignmentExtension{
+		UserId:              pl.UserId,
+		DueDate:             pl.DueDate,
+		TimeLimitMultiplier: 1,
+	}
+	if pl.TimeLimitMultiplier != nil {
+		extension.TimeLimitMultiplier = *pl.TimeLimitMultiplier
+	}
+
+	if err := c.assignmentUsecase.UpdateExtension(user.Id, pl.AssignmentId, extension); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
+
+// DeleteExtension godoc
+//
+// @Summary 		Revoke an extension of a participant
+// @Description	Delete the due date and time limit extension of a participant
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				userId							path	string		true	"User ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/extensions/{userId} [delete]
+func (c *AssignmentController) DeleteExtension(ctx *fiber.Ctx) error {
+	var pl payload.ExtensionPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.assignmentUsecase.DeleteExtension(user.Id, pl.AssignmentId, pl.UserId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
+
+// ListTemplate godoc
+//
+// @Summary 		List templates of an assignment
+// @Description	List the starter code of each language, the reference solutions are listed only to admins
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/templates [get]
+func (c *AssignmentController) ListTemplate(ctx *fiber.Ctx) error {
+	var pl payload.AssignmentPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	templates, err := c.assignmentUsecase.ListTemplate(user.Id, pl.AssignmentId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, templates)
+}
+
+// UpdateTemplate godoc
+//
+// @Summary 		Upload a template of an assignment
+// @Description	Upload the starter code or the reference solution of a language
+// @Tags 				workspace
+// @Accept 			mpfd
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				language						path	string		true	"Language ID"
+// @Param				templateType				path	string		true	"STARTER or SOLUTION"
+// @Param				file								formData	file	true	"Template file"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/templates/{language}/{templateType} [put]
+func (c *AssignmentController) UpdateTemplate(ctx *fiber.Ctx) error {
+	var pl payload.UpdateTemplatePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.assignmentUsecase.UpdateTemplate(
+		user.Id, pl.AssignmentId, pl.Language, pl.Type, pl.File,
+	); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
+
+// DeleteTemplate godoc
+//
+// @Summary 		Delete a template of an assignment
+// @Description	Delete the starter code or the reference solution of a language
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				assignmentId				path	int				true	"Assignment ID"
+// @Param				language						path	string		true	"Language ID"
+// @Param				templateType				path	string		true	"STARTER or SOLUTION"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/assignments/{assignmentId}/templates/{language}/{templateType} [delete]
+func (c *AssignmentController) DeleteTemplate(ctx *fiber.Ctx) error {
+	var pl payload.TemplatePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.assignmentUsecase.DeleteTemplate(user.Id, pl.AssignmentId, pl.Language, pl.Type); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
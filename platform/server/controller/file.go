@@ -14,20 +14,23 @@ import (
 )
 
 type FileController struct {
-	validator        domain.PayloadValidator
-	filerUrl         string
-	WorkspaceUsecase domain.WorkspaceUsecase
+	validator         domain.PayloadValidator
+	filerUrl          string
+	WorkspaceUsecase  domain.WorkspaceUsecase
+	AssignmentUsecase domain.AssignmentUsecase
 }
 
 func NewFileController(
 	cfg *config.Config,
 	validator domain.PayloadValidator,
 	WorkspaceUsecase domain.WorkspaceUsecase,
+	AssignmentUsecase domain.AssignmentUsecase,
 ) *FileController {
 	return &FileController{
-		validator:        validator,
-		filerUrl:         cfg.Client.SeaweedFs.FilerUrls.Internal,
-		WorkspaceUsecase: WorkspaceUsecase,
+		validator:         validator,
+		filerUrl:          cfg.Client.SeaweedFs.FilerUrls.Internal,
+		WorkspaceUsecase:  WorkspaceUsecase,
+		AssignmentUsecase: AssignmentUsecase,
 	}
 }
 
@@ -111,16 +114,31 @@ func (c *FileController) GetAssignmentDetail(ctx *fiber.Ctx) error {
 }
 
 func (c *FileController) GetAssignmentTestcase(ctx *fiber.Ctx) error {
-	var pl payload.AssignmentPath
+	var pl payload.TestcaseFilePath
 	if ok, err := c.validator.Validate(&pl, ctx); !ok {
 		return err
 	}
 
-	testcaseFile := ctx.Params("testcaseFile")
+	// Testcases created before revisioning are stored without the revision directory
 	path := fmt.Sprintf(
 		"/workspaces/%d/assignments/%d/testcase/%s",
-		pl.WorkspaceId, pl.AssignmentId, testcaseFile,
+		pl.WorkspaceId, pl.AssignmentId, pl.TestcaseFile,
 	)
+	if pl.Revision != 0 {
+		path = fmt.Sprintf(
+			"/workspaces/%d/assignments/%d/testcase/%d/%s",
+			pl.WorkspaceId, pl.AssignmentId, pl.Revision, pl.TestcaseFile,
+		)
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+	isVisible, err := c.AssignmentUsecase.IsTestcaseFileVisible(user.Id, pl.AssignmentId, path)
+	if err != nil {
+		return err
+	} else if !isVisible {
+		return errs.New(errs.ErrFilePerm, "no permission to get testcase file not sample")
+	}
+
 	url, err := url.JoinPath(c.filerUrl, path)
 	if err != nil {
 		return errs.New(errs.ErrCreateUrlPath, "invalid url", err)
@@ -128,19 +146,49 @@ func (c *FileController) GetAssignmentTestcase(ctx *fiber.Ctx) error {
 	return proxy.Forward(url)(ctx)
 }
 
-func (c *FileController) GetSubmission(ctx *fiber.Ctx) error {
-	var pl payload.SubmissionPath
+func (c *FileController) GetAssignmentTemplate(ctx *fiber.Ctx) error {
+	var pl payload.TemplateFilePath
 	if ok, err := c.validator.Validate(&pl, ctx); !ok {
 		return err
 	}
 
 	user := middleware.GetUserFromCtx(ctx)
-	submittedUserId := ctx.Params("userId")
+	if pl.TemplateType == "solution" {
+		isAuthorized, err := c.WorkspaceUsecase.CheckPerm(user.Id, pl.WorkspaceId)
+		if err != nil {
+			return err
+		} else if !isAuthorized {
+			return errs.New(errs.ErrFilePerm, "no permission to get reference solution")
+		}
+	}
+
+	// Getting with status hides an unpublished assignment from a member
+	assignment, err := c.AssignmentUsecase.GetWithStatus(pl.AssignmentId, user.Id)
+	if err != nil {
+		return err
+	} else if assignment == nil || assignment.WorkspaceId != pl.WorkspaceId {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", pl.AssignmentId)
+	}
 
 	path := fmt.Sprintf(
-		"/workspaces/%d/assignments/%d/submissions/%s/%d",
-		pl.WorkspaceId, pl.AssignmentId, submittedUserId, pl.SubmissionId,
+		"/workspaces/%d/assignments/%d/template/%s/%s",
+		pl.WorkspaceId, pl.AssignmentId, pl.TemplateType, pl.TemplateFile,
 	)
+	url, err := url.JoinPath(c.filerUrl, path)
+	if err != nil {
+		return errs.New(errs.ErrCreateUrlPath, "invalid url", err)
+	}
+	return proxy.Forward(url)(ctx)
+}
+
+func (c *FileController) GetSubmission(ctx *fiber.Ctx) error {
+	var pl payload.SubmissionPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+	submittedUserId := ctx.Params("userId")
 
 	userRole, err := c.WorkspaceUsecase.GetRole(user.Id, pl.WorkspaceId)
 	if err != nil {
@@ -149,6 +197,33 @@ func (c *FileController) GetSubmission(ctx *fiber.Ctx) error {
 		return errs.New(errs.ErrFilePerm, "no permission to get file not own", err)
 	}
 
+	// The path is taken from the submission instead of the url to not escape it by dot segments
+	submission, err := c.AssignmentUsecase.GetSubmission(pl.SubmissionId)
+	if err != nil {
+		return err
+	} else if submission == nil || submission.AssignmentId != pl.AssignmentId || submission.SubmitterId != submittedUserId {
+		return errs.New(errs.ErrSubmissionNotFound, "submission id %d not found", pl.SubmissionId)
+	}
+
+	path := submission.FileUrl
+	// A file of a multi-file submission
+	if subPath := ctx.Params("*"); subPath != "" {
+		subPath, err := url.PathUnescape(subPath)
+		if err != nil {
+			return errs.New(errs.ErrParamsParser, "invalid file path", err)
+		}
+		path = ""
+		for i := range submission.Files {
+			if submission.Files[i].Path == subPath {
+				path = submission.Files[i].FileUrl
+				break
+			}
+		}
+		if path == "" {
+			return errs.New(errs.ErrSubmissionNotFound, "file %s of submission id %d not found", subPath, pl.SubmissionId)
+		}
+	}
+
 	url, err := url.JoinPath(c.filerUrl, path)
 	if err != nil {
 		return errs.New(errs.ErrCreateUrlPath, "invalid url", err)
@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type GradingController struct {
+	validator domain.PayloadValidator
+
+	gradingUsecase domain.GradingUsecase
+}
+
+func NewGradingController(
+	validator domain.PayloadValidator,
+	gradingUsecase domain.GradingUsecase,
+) *GradingController {
+	return &GradingController{
+		validator:      validator,
+		gradingUsecase: gradingUsecase,
+	}
+}
+
+// ListDeadLetter godoc
+//
+// @Summary 		List dead letters
+// @Description	Get all grading messages routed to the dead-letter queue
+// @Tags 				admin
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/admin/dead-letters [get]
+func (c *GradingController) ListDeadLetter(ctx *fiber.Ctx) error {
+	deadLetters, err := c.gradingUsecase.ListDeadLetter()
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, deadLetters)
+}
+
+// GetDeadLetter godoc
+//
+// @Summary 		Get a dead letter
+// @Description	Get a grading message routed to the dead-letter queue
+// @Tags 				admin
+// @Produce 		json
+// @Param				deadLetterId				path	int				true	"Dead letter ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/admin/dead-letters/{deadLetterId} [get]
+func (c *GradingController) GetDeadLetter(ctx *fiber.Ctx) error {
+	var pl payload.DeadLetterPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	deadLetter, err := c.gradingUsecase.GetDeadLetter(pl.DeadLetterId)
+	if err != nil {
+		return err
+	} else if deadLetter == nil {
+		return errs.New(errs.ErrDeadLetterNotFound, "dead letter id %d not found", pl.DeadLetterId)
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, deadLetter)
+}
+
+// ReplayDeadLetter godoc
+//
+// @Summary 		Replay a dead letter
+// @Description	Publish a dead-lettered grading message back to its original queue
+// @Tags 				admin
+// @Produce 		json
+// @Param				deadLetterId				path	int				true	"Dead letter ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/admin/dead-letters/{deadLetterId}/replay [post]
+func (c *GradingController) ReplayDeadLetter(ctx *fiber.Ctx) error {
+	var pl payload.DeadLetterPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	if err := c.gradingUsecase.ReplayDeadLetter(pl.DeadLetterId); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
+		"replayed_at": time.Now(),
+	})
+}
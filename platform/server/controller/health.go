@@ -5,6 +5,7 @@ import (
 
 	"github.com/codern-org/codern/internal/config"
 	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/platform"
 	"github.com/codern-org/codern/platform/server/response"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
@@ -13,11 +14,15 @@ import (
 )
 
 type HealthController struct {
-	cfg *config.Config
+	cfg      *config.Config
+	rabbitMq *platform.RabbitMq
 }
 
-func NewHealthController(cfg *config.Config) *HealthController {
-	return &HealthController{cfg: cfg}
+func NewHealthController(cfg *config.Config, rabbitMq *platform.RabbitMq) *HealthController {
+	return &HealthController{
+		cfg:      cfg,
+		rabbitMq: rabbitMq,
+	}
 }
 
 func (c *HealthController) Index(ctx *fiber.Ctx) error {
@@ -34,6 +39,9 @@ func (c *HealthController) Check(ctx *fiber.Ctx) error {
 	}
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, fiber.Map{
 		"hostname": hostname,
+		"rabbitmq": fiber.Map{
+			"connected": c.rabbitMq.IsConnected(),
+		},
 	})
 }
 
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform/server/middleware"
+	"github.com/codern-org/codern/platform/server/payload"
+	"github.com/codern-org/codern/platform/server/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LanguageController struct {
+	validator domain.PayloadValidator
+
+	languageUsecase domain.LanguageUsecase
+}
+
+func NewLanguageController(
+	validator domain.PayloadValidator,
+	languageUsecase domain.LanguageUsecase,
+) *LanguageController {
+	return &LanguageController{
+		validator:       validator,
+		languageUsecase: languageUsecase,
+	}
+}
+
+// List godoc
+//
+// @Summary 		List languages
+// @Description	Get all languages supported by the grader
+// @Tags 				language
+// @Produce 		json
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/languages [get]
+func (c *LanguageController) List(ctx *fiber.Ctx) error {
+	languages, err := c.languageUsecase.List()
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, languages)
+}
+
+// ListByWorkspace godoc
+//
+// @Summary 		List workspace languages
+// @Description	Get all languages with the enabled flag of a workspace
+// @Tags 				workspace
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/languages [get]
+func (c *LanguageController) ListByWorkspace(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	languages, err := c.languageUsecase.ListByWorkspace(pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, languages)
+}
+
+// UpdateWorkspaceLanguage godoc
+//
+// @Summary 		Update workspace language
+// @Description	Enable or disable a language in a workspace
+// @Tags 				workspace
+// @Accept 			json
+// @Produce 		json
+// @Param				workspaceId					path	int				true	"Workspace ID"
+// @Param				languageId					path	string		true	"Language ID"
+// @Param				payload							body	payload.UpdateWorkspaceLanguagePayload true "Payload"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/languages/{languageId} [patch]
+func (c *LanguageController) UpdateWorkspaceLanguage(ctx *fiber.Ctx) error {
+	var pl payload.UpdateWorkspaceLanguagePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.languageUsecase.UpdateWorkspaceLanguage(
+		user.Id,
+		pl.WorkspaceId,
+		pl.LanguageId,
+		*pl.IsEnabled,
+	); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
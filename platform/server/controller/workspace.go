@@ -14,16 +14,19 @@ import (
 type WorkspaceController struct {
 	validator domain.PayloadValidator
 
-	workspaceUsecase domain.WorkspaceUsecase
+	workspaceUsecase  domain.WorkspaceUsecase
+	assignmentUsecase domain.AssignmentUsecase
 }
 
 func NewWorkspaceController(
 	validator domain.PayloadValidator,
 	workspaceUsecase domain.WorkspaceUsecase,
+	assignmentUsecase domain.AssignmentUsecase,
 ) *WorkspaceController {
 	return &WorkspaceController{
-		validator:        validator,
-		workspaceUsecase: workspaceUsecase,
+		validator:         validator,
+		workspaceUsecase:  workspaceUsecase,
+		assignmentUsecase: assignmentUsecase,
 	}
 }
 
@@ -49,6 +52,36 @@ func (c *WorkspaceController) Create(ctx *fiber.Ctx) error {
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, workspace)
 }
 
+// Clone godoc
+//
+// @Summary 		Clone a workspace
+// @Description	Create a workspace with the profile, admins, owners, levels and assignments of a workspace,
+// @Description	the assignments are rescheduled to start from the start date without submissions and members,
+// @Description	assignments without testcases are skipped and the workspace is deleted when cloning fails
+// @Tags 				workspace
+// @Accept 			json
+// @Produce 		json
+// @Param				workspaceId			path	int		true	"Workspace ID"
+// @Param				payload					body	payload.CloneWorkspacePayload true "Payload"
+// @Security 		ApiKeyAuth
+// @Param 			sid header string true "Session ID"
+// @Router 			/workspaces/{workspaceId}/clone [post]
+func (c *WorkspaceController) Clone(ctx *fiber.Ctx) error {
+	var pl payload.CloneWorkspacePayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	workspace, err := c.assignmentUsecase.CloneWorkspace(user.Id, pl.WorkspaceId, pl.Name, pl.StartDate)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, workspace)
+}
+
 // List godoc
 //
 // @Summary 		List workspaces
@@ -277,3 +310,47 @@ func (c *WorkspaceController) Delete(ctx *fiber.Ctx) error {
 
 	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
 }
+
+func (c *WorkspaceController) ListLevel(ctx *fiber.Ctx) error {
+	var pl payload.WorkspacePath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	levels, err := c.workspaceUsecase.ListLevel(pl.WorkspaceId)
+	if err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, levels)
+}
+
+func (c *WorkspaceController) UpdateLevel(ctx *fiber.Ctx) error {
+	var pl payload.UpdateWorkspaceLevelPayload
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.workspaceUsecase.UpdateLevel(user.Id, pl.WorkspaceId, pl.Level, pl.Score); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
+
+func (c *WorkspaceController) DeleteLevel(ctx *fiber.Ctx) error {
+	var pl payload.WorkspaceLevelPath
+	if ok, err := c.validator.Validate(&pl, ctx); !ok {
+		return err
+	}
+
+	user := middleware.GetUserFromCtx(ctx)
+
+	if err := c.workspaceUsecase.DeleteLevel(user.Id, pl.WorkspaceId, pl.Level); err != nil {
+		return err
+	}
+
+	return response.NewSuccessResponse(ctx, fiber.StatusOK, nil)
+}
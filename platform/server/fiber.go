@@ -95,21 +95,24 @@ func (s *FiberServer) applyRoutes() {
 	// Initialize Middlewares
 	fileMiddleware := middleware.NewFileMiddleware()
 	authMiddleware := middleware.NewAuthMiddleware(validator, s.usecase.Auth)
+	adminMiddleware := middleware.NewAdminMiddleware(s.cfg)
 	publishableWorkspaceMiddleware := middleware.NewPublishableWorkspaceMiddleware(validator, s.usecase.Auth, s.usecase.Workspace)
 	workspaceMiddleware := middleware.NewWorkspaceMiddleware(validator, s.usecase.Workspace)
 	scoreboardMiddleware := middleware.NewScoreboardMiddleware(validator, s.usecase.Auth, s.usecase.Workspace, s.usecase.Misc)
 
 	// Initialize Controllers
-	healtController := controller.NewHealthController(s.cfg)
+	healtController := controller.NewHealthController(s.cfg, s.platform.RabbitMq)
 	webSocketController := controller.NewWebSocketController(s.platform.WebSocketHub)
-	fileController := controller.NewFileController(s.cfg, validator, s.usecase.Workspace)
+	fileController := controller.NewFileController(s.cfg, validator, s.usecase.Workspace, s.usecase.Assignment)
 	authController := controller.NewAuthController(
 		s.cfg, validator, s.usecase.Auth, s.usecase.Google, s.usecase.User,
 	)
-	workspaceController := controller.NewWorkspaceController(validator, s.usecase.Workspace)
+	workspaceController := controller.NewWorkspaceController(validator, s.usecase.Workspace, s.usecase.Assignment)
 	assignmentController := controller.NewAssignmentController(validator, s.usecase.Assignment)
 	userController := controller.NewUserController(validator, s.usecase.User)
 	surveyController := controller.NewSurveyController(validator, s.usecase.Survey)
+	gradingController := controller.NewGradingController(validator, s.usecase.Grading)
+	languageController := controller.NewLanguageController(validator, s.usecase.Language)
 
 	// Initialize Routes
 	api := s.app.Group("/")
@@ -129,6 +132,9 @@ func (s *FiberServer) applyRoutes() {
 	user.Patch("/", authMiddleware, userController.Update)
 	user.Patch("/password", authMiddleware, userController.UpdatePassword)
 
+	language := api.Group("/languages", middleware.PathType("language"))
+	language.Get("/", authMiddleware, languageController.List)
+
 	workspace := api.Group("/workspaces", middleware.PathType("workspace"))
 	workspace.Get("/join/:invitationId", authMiddleware, workspaceController.JoinByInvitationCode)
 	workspace.Get("/", authMiddleware, workspaceMiddleware, workspaceController.List)
@@ -136,19 +142,42 @@ func (s *FiberServer) applyRoutes() {
 	workspace.Patch("/:workspaceId", authMiddleware, workspaceMiddleware, workspaceController.Update)
 	workspace.Delete("/:workspaceId", authMiddleware, workspaceMiddleware, workspaceController.Delete)
 	workspace.Get("/:workspaceId", publishableWorkspaceMiddleware, workspaceController.Get)
+	workspace.Post("/:workspaceId/clone", authMiddleware, workspaceMiddleware, workspaceController.Clone)
 	workspace.Get("/:workspaceId/participants", authMiddleware, workspaceMiddleware, workspaceController.ListParticipant)
 	workspace.Patch("/:workspaceId/participants/:userId", authMiddleware, workspaceMiddleware, workspaceController.UpdateParticipant)
 	workspace.Delete("/:workspaceId/participants/:userId", authMiddleware, workspaceMiddleware, workspaceController.DeleteParticipant)
 	workspace.Get("/:workspaceId/scoreboard", scoreboardMiddleware, cache.New(), workspaceController.GetScoreboard)
+	workspace.Get("/:workspaceId/languages", authMiddleware, workspaceMiddleware, languageController.ListByWorkspace)
+	workspace.Patch("/:workspaceId/languages/:languageId", authMiddleware, workspaceMiddleware, languageController.UpdateWorkspaceLanguage)
+	workspace.Get("/:workspaceId/levels", authMiddleware, workspaceMiddleware, workspaceController.ListLevel)
+	workspace.Put("/:workspaceId/levels/:level", authMiddleware, workspaceMiddleware, workspaceController.UpdateLevel)
+	workspace.Delete("/:workspaceId/levels/:level", authMiddleware, workspaceMiddleware, workspaceController.DeleteLevel)
 
 	assignment := workspace.Group("/:workspaceId/assignments")
 	assignment.Get("/", authMiddleware, workspaceMiddleware, assignmentController.List)
 	assignment.Post("/", authMiddleware, workspaceMiddleware, assignmentController.Create)
+	assignment.Post("/import", authMiddleware, workspaceMiddleware, assignmentController.Import)
 	assignment.Get("/:assignmentId", authMiddleware, workspaceMiddleware, assignmentController.Get)
 	assignment.Patch("/:assignmentId", authMiddleware, workspaceMiddleware, assignmentController.Update)
 	assignment.Delete("/:assignmentId", authMiddleware, workspaceMiddleware, assignmentController.Delete)
 	assignment.Get("/:assignmentId/submissions", authMiddleware, workspaceMiddleware, assignmentController.ListSubmission)
 	assignment.Post("/:assignmentId/submissions", authMiddleware, workspaceMiddleware, assignmentController.CreateSubmission)
+	assignment.Get("/:assignmentId/submissions/:submissionId/diff/:otherSubmissionId", authMiddleware, workspaceMiddleware, assignmentController.DiffSubmission)
+	assignment.Post("/:assignmentId/regrade", authMiddleware, workspaceMiddleware, assignmentController.Regrade)
+	assignment.Get("/:assignmentId/regrade/:regradeJobId", authMiddleware, workspaceMiddleware, assignmentController.GetRegradeJob)
+	assignment.Post("/:assignmentId/similarity", authMiddleware, workspaceMiddleware, assignmentController.DetectSimilarity)
+	assignment.Get("/:assignmentId/similarity/:similarityJobId", authMiddleware, workspaceMiddleware, assignmentController.GetSimilarityReport)
+	assignment.Get("/:assignmentId/similarity/:similarityJobId/pairs/:submissionId/:otherSubmissionId", authMiddleware, workspaceMiddleware, assignmentController.CompareSimilarity)
+	assignment.Get("/:assignmentId/export", authMiddleware, workspaceMiddleware, assignmentController.Export)
+	assignment.Post("/:assignmentId/copy", authMiddleware, workspaceMiddleware, assignmentController.Copy)
+	assignment.Get("/:assignmentId/testcases/export", authMiddleware, workspaceMiddleware, assignmentController.ExportTestcases)
+	assignment.Get("/:assignmentId/testcase-generation", authMiddleware, workspaceMiddleware, assignmentController.GetTestcaseGeneration)
+	assignment.Get("/:assignmentId/extensions", authMiddleware, workspaceMiddleware, assignmentController.ListExtension)
+	assignment.Put("/:assignmentId/extensions/:userId", authMiddleware, workspaceMiddleware, assignmentController.UpdateExtension)
+	assignment.Delete("/:assignmentId/extensions/:userId", authMiddleware, workspaceMiddleware, assignmentController.DeleteExtension)
+	assignment.Get("/:assignmentId/templates", authMiddleware, workspaceMiddleware, assignmentController.ListTemplate)
+	assignment.Put("/:assignmentId/templates/:language/:templateType", authMiddleware, workspaceMiddleware, assignmentController.UpdateTemplate)
+	assignment.Delete("/:assignmentId/templates/:language/:templateType", authMiddleware, workspaceMiddleware, assignmentController.DeleteTemplate)
 
 	invitation := workspace.Group("/:workspaceId/invitation", middleware.PathType("invitation"))
 	invitation.Get("/", authMiddleware, workspaceMiddleware, workspaceController.GetInvitations)
@@ -158,13 +187,21 @@ func (s *FiberServer) applyRoutes() {
 	survey := s.app.Group("/survey")
 	survey.Post("/", authMiddleware, surveyController.CreateSurvey)
 
+	admin := api.Group("/admin", middleware.PathType("admin"), authMiddleware, adminMiddleware)
+	admin.Get("/dead-letters", gradingController.ListDeadLetter)
+	admin.Get("/dead-letters/:deadLetterId", gradingController.GetDeadLetter)
+	admin.Post("/dead-letters/:deadLetterId/replay", gradingController.ReplayDeadLetter)
+
 	// File proxy from SeaweedFS
 	fs := s.app.Group("/file", middleware.PathType("file"), fileMiddleware)
 	fs.Get("/user/:userId/profile", fileController.GetUserProfile)
 	fs.Get("/workspaces/:workspaceId/profile", fileController.GetWorkspaceProfile)
 	fs.Get("/workspaces/:workspaceId/assignments/:assignmentId/detail/*", authMiddleware, workspaceMiddleware, fileController.GetAssignmentDetail)
 	fs.Get("/workspaces/:workspaceId/assignments/:assignmentId/testcase/:testcaseFile", authMiddleware, workspaceMiddleware, fileController.GetAssignmentTestcase)
+	fs.Get("/workspaces/:workspaceId/assignments/:assignmentId/testcase/:revision/:testcaseFile", authMiddleware, workspaceMiddleware, fileController.GetAssignmentTestcase)
+	fs.Get("/workspaces/:workspaceId/assignments/:assignmentId/template/:templateType/:templateFile", authMiddleware, workspaceMiddleware, fileController.GetAssignmentTemplate)
 	fs.Get("/workspaces/:workspaceId/assignments/:assignmentId/submissions/:userId/:submissionId", authMiddleware, workspaceMiddleware, fileController.GetSubmission)
+	fs.Get("/workspaces/:workspaceId/assignments/:assignmentId/submissions/:userId/:submissionId/*", authMiddleware, workspaceMiddleware, fileController.GetSubmission)
 
 	// WebSocket
 	ws := s.app.Group("/ws", authMiddleware, webSocketController.Upgrade)
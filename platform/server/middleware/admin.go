@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/gofiber/fiber/v2"
+)
+
+func NewAdminMiddleware(cfg *config.Config) fiber.Handler {
+	admins := make(map[string]bool)
+	for _, userId := range cfg.Auth.Admins {
+		admins[userId] = true
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		user := GetUserFromCtx(ctx)
+		if user == nil || !admins[user.Id] {
+			return errs.New(errs.ErrAdminNoPerm, "permission denied")
+		}
+		return ctx.Next()
+	}
+}
@@ -1,11 +1,16 @@
 package payload
 
 import (
+	"bytes"
+	"io"
 	"mime/multipart"
+	"sort"
 	"time"
 
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/archive"
+	"github.com/codern-org/codern/internal/constant"
 )
 
 type SubmissionPath struct {
@@ -13,45 +18,166 @@ type SubmissionPath struct {
 	SubmissionId int `params:"submissionId" validate:"required" json:"-"`
 }
 
+type SubmissionDiffPath struct {
+	SubmissionPath
+	OtherSubmissionId int `params:"otherSubmissionId" validate:"required" json:"-"`
+}
+
+type TestcaseFilePath struct {
+	AssignmentPath
+	Revision     int    `params:"revision" json:"-"`
+	TestcaseFile string `params:"testcaseFile" validate:"required" json:"-"`
+}
+
+type RegradeJobPath struct {
+	AssignmentPath
+	RegradeJobId int `params:"regradeJobId" validate:"required" json:"-"`
+}
+
+type SimilarityJobPath struct {
+	AssignmentPath
+	SimilarityJobId int `params:"similarityJobId" validate:"required" json:"-"`
+}
+
+type SimilarityPairPath struct {
+	SimilarityJobPath
+	SubmissionId      int `params:"submissionId" validate:"required" json:"-"`
+	OtherSubmissionId int `params:"otherSubmissionId" validate:"required" json:"-"`
+}
+
+type ExtensionPath struct {
+	AssignmentPath
+	UserId string `params:"userId" validate:"required" json:"-"`
+}
+
+type UpdateExtensionPayload struct {
+	ExtensionPath
+	DueDate             *time.Time `json:"dueDate"`
+	TimeLimitMultiplier *float64   `json:"timeLimitMultiplier" validate:"omitempty,gt=0"`
+}
+
+type TemplatePath struct {
+	AssignmentPath
+	Language string              `params:"language" validate:"required" json:"-"`
+	Type     domain.TemplateType `params:"templateType" validate:"required,oneof=STARTER SOLUTION" json:"-"`
+}
+
+type UpdateTemplatePayload struct {
+	TemplatePath
+	File multipart.File `file:"file" validate:"required"`
+}
+
+type TemplateFilePath struct {
+	AssignmentPath
+	TemplateType string `params:"templateType" validate:"required,oneof=starter solution" json:"-"`
+	TemplateFile string `params:"templateFile" validate:"required" json:"-"`
+}
+
+type RegradePayload struct {
+	AssignmentPath
+	LatestOnly bool                     `json:"latestOnly"`
+	UserIds    []string                 `json:"userIds"`
+	Status     *domain.AssignmentStatus `json:"status" validate:"omitempty,oneof=COMPLETED INCOMPLETED GRADING SYSTEM_FAILURE"`
+}
+
 type CreateSubmissionPayload struct {
 	AssignmentPath
-	Language   string         `form:"language" validate:"required"`
-	SourceCode multipart.File `file:"sourcecode" validate:"required"`
+	Language    string                  `form:"language" validate:"required"`
+	SourceCode  multipart.File          `file:"sourcecode"`
+	SourceFiles []*multipart.FileHeader `file:"sourcefiles"`
+	SourcePaths []string                `form:"sourcePaths"`
+	Archive     *multipart.FileHeader   `file:"archive"`
+	EntryPoint  string                  `form:"entryPoint"`
 }
 
 type CreateAssignmentPayload struct {
 	WorkspacePath
-	Name                string                 `json:"name" validate:"required"`
-	Description         string                 `json:"description" validate:"required"`
-	MemoryLimit         int                    `json:"memoryLimit" validate:"required"`
-	TimeLimit           int                    `json:"timeLimit" validate:"required"`
-	Level               domain.AssignmentLevel `json:"level" validate:"required"`
-	PublishDate         time.Time              `json:"publishDate" validate:"required"`
-	DueDate             *time.Time             `json:"dueDate"`
-	DetailFile          multipart.File         `file:"detail" validate:"required"`
-	TestcaseInputFiles  []multipart.File       `file:"testcaseInput" validate:"required"`
-	TestcaseOutputFiles []multipart.File       `file:"testcaseOutput" validate:"required"`
+	Name                 string                      `json:"name" validate:"required"`
+	Description          string                      `json:"description" validate:"required"`
+	MemoryLimit          int                         `json:"memoryLimit" validate:"required"`
+	TimeLimit            int                         `json:"timeLimit" validate:"required"`
+	Level                domain.AssignmentLevel      `json:"level" validate:"required"`
+	MaxScore             *float64                    `json:"maxScore" validate:"omitempty,gt=0"`
+	GradingPriority      *domain.GradingPriority     `json:"gradingPriority" validate:"omitempty,oneof=LOW NORMAL HIGH"`
+	PublishDate          time.Time                   `json:"publishDate" validate:"required"`
+	DueDate              *time.Time                  `json:"dueDate"`
+	LatePolicy           *domain.LatePolicy          `json:"latePolicy" validate:"omitempty,oneof=NONE CUTOFF LINEAR STEP"`
+	LatePenalty          *float64                    `json:"latePenalty" validate:"omitempty,gte=0,lte=100"`
+	LatePenaltyInterval  *domain.LatePenaltyInterval `json:"latePenaltyInterval" validate:"omitempty,oneof=HOUR DAY"`
+	LateGracePeriod      *int                        `json:"lateGracePeriod" validate:"omitempty,gte=0"`
+	MaxAttempt           *int                        `json:"maxAttempt" validate:"omitempty,gte=0"`
+	SubmissionInterval   *int                        `json:"submissionInterval" validate:"omitempty,gte=0"`
+	CheckerType          *domain.CheckerType         `json:"checkerType" validate:"omitempty,oneof=EXACT TOKEN FLOAT UNORDERED CUSTOM"`
+	CheckerEpsilon       *float64                    `json:"checkerEpsilon" validate:"omitempty,gt=0"`
+	CheckerLanguage      *string                     `json:"checkerLanguage"`
+	CheckerFile          multipart.File              `file:"checker"`
+	DetailFile           multipart.File              `file:"detail" validate:"required"`
+	TestcaseArchive      *multipart.FileHeader       `file:"testcaseArchive"`
+	TestcaseInputFiles   []multipart.File            `file:"testcaseInput" validate:"required_without=TestcaseArchive"`
+	TestcaseOutputFiles  []multipart.File            `file:"testcaseOutput" validate:"required_without_all=SolutionLanguage TestcaseArchive"`
+	TestcaseGroups       []int                       `json:"testcaseGroups" validate:"dive,gte=1"`
+	TestcaseWeights      []float64                   `json:"testcaseWeights" validate:"dive,gt=0"`
+	TestcaseVisibilities []domain.TestcaseVisibility `json:"testcaseVisibilities" validate:"dive,oneof=SAMPLE HIDDEN SECRET"`
+	SolutionLanguage     *string                     `json:"solutionLanguage" validate:"required_with=SolutionFile"`
+	SolutionFile         multipart.File              `file:"solution"`
 }
 
 type UpdateAssignment struct {
 	AssignmentPath
-	Name                *string                 `json:"name"`
-	Description         *string                 `json:"description"`
-	MemoryLimit         *int                    `json:"memoryLimit"`
-	TimeLimit           *int                    `json:"timeLimit"`
-	Level               *domain.AssignmentLevel `json:"level"`
-	PublishDate         *time.Time              `json:"publishDate"`
-	DueDate             *time.Time              `json:"dueDate"`
-	DetailFile          multipart.File          `file:"detail"`
-	TestcaseInputFiles  []multipart.File        `file:"testcaseInput"`
-	TestcaseOutputFiles []multipart.File        `file:"testcaseOutput"`
+	Name                 *string                     `json:"name"`
+	Description          *string                     `json:"description"`
+	MemoryLimit          *int                        `json:"memoryLimit"`
+	TimeLimit            *int                        `json:"timeLimit"`
+	Level                *domain.AssignmentLevel     `json:"level"`
+	MaxScore             *float64                    `json:"maxScore" validate:"omitempty,gte=0"`
+	GradingPriority      *domain.GradingPriority     `json:"gradingPriority" validate:"omitempty,oneof=LOW NORMAL HIGH"`
+	PublishDate          *time.Time                  `json:"publishDate"`
+	DueDate              *time.Time                  `json:"dueDate"`
+	LatePolicy           *domain.LatePolicy          `json:"latePolicy" validate:"omitempty,oneof=NONE CUTOFF LINEAR STEP"`
+	LatePenalty          *float64                    `json:"latePenalty" validate:"omitempty,gte=0,lte=100"`
+	LatePenaltyInterval  *domain.LatePenaltyInterval `json:"latePenaltyInterval" validate:"omitempty,oneof=HOUR DAY"`
+	LateGracePeriod      *int                        `json:"lateGracePeriod" validate:"omitempty,gte=0"`
+	MaxAttempt           *int                        `json:"maxAttempt" validate:"omitempty,gte=0"`
+	SubmissionInterval   *int                        `json:"submissionInterval" validate:"omitempty,gte=0"`
+	CheckerType          *domain.CheckerType         `json:"checkerType" validate:"omitempty,oneof=EXACT TOKEN FLOAT UNORDERED CUSTOM"`
+	CheckerEpsilon       *float64                    `json:"checkerEpsilon" validate:"omitempty,gt=0"`
+	CheckerLanguage      *string                     `json:"checkerLanguage"`
+	CheckerFile          multipart.File              `file:"checker"`
+	DetailFile           multipart.File              `file:"detail"`
+	TestcaseArchive      *multipart.FileHeader       `file:"testcaseArchive"`
+	TestcaseInputFiles   []multipart.File            `file:"testcaseInput"`
+	TestcaseOutputFiles  []multipart.File            `file:"testcaseOutput"`
+	TestcaseGroups       []int                       `json:"testcaseGroups" validate:"dive,gte=1"`
+	TestcaseWeights      []float64                   `json:"testcaseWeights" validate:"dive,gt=0"`
+	TestcaseVisibilities []domain.TestcaseVisibility `json:"testcaseVisibilities" validate:"dive,oneof=SAMPLE HIDDEN SECRET"`
+	SolutionLanguage     *string                     `json:"solutionLanguage" validate:"required_with=SolutionFile"`
+	SolutionFile         multipart.File              `file:"solution"`
 }
 
 type DeleteAssignment struct {
 	AssignmentPath
 }
 
-func ValidateTestcaseFiles(inputs []multipart.File, outputs []multipart.File) error {
+type ImportAssignmentPayload struct {
+	WorkspacePath
+	Package     *multipart.FileHeader `file:"package" validate:"required"`
+	PublishDate *time.Time            `json:"publishDate"`
+	DueDate     *time.Time            `json:"dueDate"`
+}
+
+type CopyAssignmentPayload struct {
+	AssignmentPath
+	TargetWorkspaceId int        `json:"targetWorkspaceId" validate:"required"`
+	PublishDate       *time.Time `json:"publishDate"`
+	DueDate           *time.Time `json:"dueDate"`
+}
+
+// ValidateTestcaseFiles checks that every input has its output,
+// outputs are not uploaded when they are generated from a reference solution
+func ValidateTestcaseFiles(inputs []multipart.File, outputs []multipart.File, solutionLanguage *string) error {
+	if solutionLanguage != nil {
+		return nil
+	}
 	if len(inputs) != len(outputs) {
 		return errs.NewPayloadError([]errs.ValidationErrorDetail{
 			{
@@ -66,3 +192,176 @@ func ValidateTestcaseFiles(inputs []multipart.File, outputs []multipart.File) er
 	}
 	return nil
 }
+
+// ValidateTestcaseGroups checks that groups, weights and visibilities, if given, are paired with every testcase
+func ValidateTestcaseGroups(
+	inputs []multipart.File,
+	groups []int,
+	weights []float64,
+	visibilities []domain.TestcaseVisibility,
+) error {
+	details := make([]errs.ValidationErrorDetail, 0)
+	if len(groups) > 0 && len(groups) != len(inputs) {
+		details = append(details, errs.ValidationErrorDetail{
+			Field: "TestcaseGroups",
+			Type:  "length_mismatch",
+		})
+	}
+	if len(weights) > 0 && len(weights) != len(inputs) {
+		details = append(details, errs.ValidationErrorDetail{
+			Field: "TestcaseWeights",
+			Type:  "length_mismatch",
+		})
+	}
+	if len(visibilities) > 0 && len(visibilities) != len(inputs) {
+		details = append(details, errs.ValidationErrorDetail{
+			Field: "TestcaseVisibilities",
+			Type:  "length_mismatch",
+		})
+	}
+	if len(details) > 0 {
+		return errs.NewPayloadError(details)
+	}
+	return nil
+}
+
+// GetTestcaseArchive reads the testcases of a zip archive
+func GetTestcaseArchive(header *multipart.FileHeader, solutionLanguage *string) ([]domain.TestcaseFile, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, errs.New(errs.ErrBodyParser, "cannot parse the file", err)
+	}
+	defer file.Close()
+
+	files, err := archive.ReadZip(file, header.Size, int64(constant.MaxTestcaseArchiveSize))
+	if err != nil {
+		return nil, errs.New(errs.ErrInvalidTestcaseArchive, "cannot read testcase archive", err)
+	}
+	// Outputs are generated by the reference solution of the solution language
+	return archive.ReadTestcases(files, solutionLanguage == nil)
+}
+
+// GetSubmissionSource reads exactly one of a source code, source files or a zip archive,
+// source files and an archive are a project that requires an entry point. An uploaded file keeps
+// only its base name, so source files in directories are given their paths in order by source paths
+func GetSubmissionSource(pl *CreateSubmissionPayload) (*domain.SubmissionSource, error) {
+	sourceCount := 0
+	if pl.SourceCode != nil {
+		sourceCount++
+	}
+	if len(pl.SourceFiles) > 0 {
+		sourceCount++
+	}
+	if pl.Archive != nil {
+		sourceCount++
+	}
+	if sourceCount != 1 {
+		return nil, errs.NewPayloadError([]errs.ValidationErrorDetail{
+			{
+				Field: "SourceCode",
+				Type:  "required_one",
+			},
+		})
+	}
+
+	if pl.SourceCode != nil {
+		return &domain.SubmissionSource{File: pl.SourceCode}, nil
+	}
+
+	if pl.EntryPoint == "" {
+		return nil, errs.NewPayloadError([]errs.ValidationErrorDetail{
+			{
+				Field: "EntryPoint",
+				Type:  "required",
+			},
+		})
+	}
+
+	source := &domain.SubmissionSource{EntryPoint: pl.EntryPoint}
+	if pl.Archive != nil {
+		files, err := extractSourceArchive(pl.Archive)
+		if err != nil {
+			return nil, err
+		}
+		source.Files = files
+		return source, nil
+	}
+
+	if len(pl.SourcePaths) > 0 && len(pl.SourcePaths) != len(pl.SourceFiles) {
+		return nil, errs.NewPayloadError([]errs.ValidationErrorDetail{
+			{
+				Field: "SourcePaths",
+				Type:  "length_mismatch",
+			},
+		})
+	}
+
+	totalSize := int64(0)
+	for i, header := range pl.SourceFiles {
+		// Checked before reading to not load an oversized submission into memory
+		totalSize += header.Size
+		if totalSize > int64(constant.MaxSubmissionSize) {
+			return nil, errs.New(errs.ErrSubmissionTooLarge, "submission exceeds %d bytes", constant.MaxSubmissionSize)
+		}
+		content, err := readSourceFile(header)
+		if err != nil {
+			return nil, err
+		}
+		filePath := header.Filename
+		if len(pl.SourcePaths) > 0 {
+			filePath = pl.SourcePaths[i]
+		}
+		source.Files = append(source.Files, domain.SourceFile{
+			Path:   filePath,
+			Reader: bytes.NewReader(content),
+			Size:   len(content),
+		})
+	}
+	return source, nil
+}
+
+// extractSourceArchive reads the files of a zip archive into memory sorted by path,
+// stopping at the submission size to not inflate a zip bomb
+func extractSourceArchive(header *multipart.FileHeader) ([]domain.SourceFile, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, errs.New(errs.ErrBodyParser, "cannot parse the file", err)
+	}
+	defer file.Close()
+
+	contents, err := archive.ReadZip(file, header.Size, int64(constant.MaxSubmissionSize))
+	if err != nil {
+		return nil, errs.New(errs.ErrInvalidSubmissionFile, "cannot read submission archive", err)
+	}
+
+	paths := make([]string, 0, len(contents))
+	for filePath := range contents {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	files := make([]domain.SourceFile, 0, len(paths))
+	for _, filePath := range paths {
+		files = append(files, domain.SourceFile{
+			Path:   filePath,
+			Reader: bytes.NewReader(contents[filePath]),
+			Size:   len(contents[filePath]),
+		})
+	}
+	return files, nil
+}
+
+// readSourceFile reads an uploaded file into memory to close it right away
+func readSourceFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, errs.New(errs.ErrBodyParser, "cannot parse the file", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, errs.New(errs.ErrBodyParser, "cannot read the file %s", header.Filename, err)
+	}
+	return content, nil
+}
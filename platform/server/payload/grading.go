@@ -0,0 +1,5 @@
+package payload
+
+type DeadLetterPath struct {
+	DeadLetterId int `params:"deadLetterId" validate:"required" json:"-"`
+}
@@ -0,0 +1,11 @@
+package payload
+
+type WorkspaceLanguagePath struct {
+	WorkspacePath
+	LanguageId string `params:"languageId" validate:"required" json:"-"`
+}
+
+type UpdateWorkspaceLanguagePayload struct {
+	WorkspaceLanguagePath
+	IsEnabled *bool `json:"isEnabled" validate:"required"`
+}
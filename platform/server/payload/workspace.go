@@ -3,6 +3,8 @@ package payload
 import (
 	"mime/multipart"
 	"time"
+
+	"github.com/codern-org/codern/domain"
 )
 
 type WorkspacePath struct {
@@ -14,6 +16,11 @@ type WorkspaceParticipantPath struct {
 	UserId string `params:"userId" validate:"required" json:"-"`
 }
 
+type WorkspaceLevelPath struct {
+	WorkspacePath
+	Level domain.AssignmentLevel `params:"level" validate:"required" json:"-"`
+}
+
 type AssignmentPath struct {
 	WorkspacePath
 	AssignmentId int `params:"assignmentId" validate:"required" json:"-"`
@@ -32,6 +39,12 @@ type UpdateWorkspacePayload struct {
 	Profile  multipart.File `file:"profile"`
 }
 
+type CloneWorkspacePayload struct {
+	WorkspacePath
+	Name      *string   `json:"name"`
+	StartDate time.Time `json:"startDate" validate:"required"`
+}
+
 type CreateInvitationPayload struct {
 	WorkspacePath
 	ValidAt    time.Time `json:"validAt" validate:"required"`
@@ -48,3 +61,8 @@ type UpdateParticipantPayload struct {
 	WorkspaceParticipantPath
 	Role string `json:"role" validate:"required"`
 }
+
+type UpdateWorkspaceLevelPayload struct {
+	WorkspaceLevelPath
+	Score float64 `json:"score" validate:"gt=0"`
+}
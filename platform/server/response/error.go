@@ -31,8 +31,14 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrGetUser:           fiber.StatusInternalServerError,
 	errs.ErrCreateUser:        fiber.StatusInternalServerError,
 	errs.ErrGoogleAuth:        fiber.StatusInternalServerError,
+	errs.ErrAdminNoPerm:       fiber.StatusForbidden,
 
-	errs.ErrGradingRequest: fiber.StatusInternalServerError,
+	errs.ErrGradingRequest:     fiber.StatusInternalServerError,
+	errs.ErrCreateDeadLetter:   fiber.StatusInternalServerError,
+	errs.ErrGetDeadLetter:      fiber.StatusInternalServerError,
+	errs.ErrListDeadLetter:     fiber.StatusInternalServerError,
+	errs.ErrDeadLetterNotFound: fiber.StatusNotFound,
+	errs.ErrReplayDeadLetter:   fiber.StatusInternalServerError,
 
 	errs.ErrFilePerm: fiber.StatusForbidden,
 
@@ -55,6 +61,10 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrUpdateWorkspace:            fiber.StatusInternalServerError,
 	errs.ErrDeleteWorkspace:            fiber.StatusInternalServerError,
 	errs.ErrWorkspaceAlreadyJoin:       fiber.StatusConflict,
+	errs.ErrListWorkspaceLevel:         fiber.StatusInternalServerError,
+	errs.ErrUpdateWorkspaceLevel:       fiber.StatusInternalServerError,
+	errs.ErrDeleteWorkspaceLevel:       fiber.StatusInternalServerError,
+	errs.ErrLevelInUse:                 fiber.StatusConflict,
 
 	errs.ErrCreateInvitation:      fiber.StatusInternalServerError,
 	errs.ErrGetInvitation:         fiber.StatusInternalServerError,
@@ -69,15 +79,67 @@ var DomainErrCodeToHttpStatus = map[int]int{
 	errs.ErrAssignmentNoTestcase: fiber.StatusInternalServerError,
 	errs.ErrCreateAssignment:     fiber.StatusInternalServerError,
 	errs.ErrUpdateAssignment:     fiber.StatusInternalServerError,
+	errs.ErrInvalidLevel:         fiber.StatusBadRequest,
+	errs.ErrInvalidChecker:       fiber.StatusBadRequest,
+	errs.ErrInvalidPackage:       fiber.StatusBadRequest,
+	errs.ErrExportPackage:        fiber.StatusInternalServerError,
 
 	errs.ErrCreateSubmission:       fiber.StatusInternalServerError,
 	errs.ErrCreateSubmissionResult: fiber.StatusInternalServerError,
 	errs.ErrGetSubmission:          fiber.StatusInternalServerError,
 	errs.ErrListSubmission:         fiber.StatusInternalServerError,
+	errs.ErrUpdateSubmission:       fiber.StatusInternalServerError,
+	errs.ErrRequeueSubmission:      fiber.StatusInternalServerError,
+	errs.ErrDupSubmissionResult:    fiber.StatusConflict,
+	errs.ErrCreateRegradeJob:       fiber.StatusInternalServerError,
+	errs.ErrGetRegradeJob:          fiber.StatusInternalServerError,
+	errs.ErrRegradeJobNotFound:     fiber.StatusNotFound,
+	errs.ErrSubmissionNoAttempt:    fiber.StatusForbidden,
+	errs.ErrSubmissionTooFrequent:  fiber.StatusTooManyRequests,
+	errs.ErrSubmissionRateLimited:  fiber.StatusTooManyRequests,
+	errs.ErrInvalidSubmissionFile:  fiber.StatusBadRequest,
+	errs.ErrSubmissionTooLarge:     fiber.StatusRequestEntityTooLarge,
+	errs.ErrSubmissionNotFound:     fiber.StatusNotFound,
+	errs.ErrInvalidSubmissionDiff:  fiber.StatusBadRequest,
 
 	errs.ErrListTestcase:   fiber.StatusInternalServerError,
 	errs.ErrCreateTestcase: fiber.StatusInternalServerError,
 	errs.ErrDeleteTestcase: fiber.StatusInternalServerError,
 
+	errs.ErrCreateTestcaseGeneration:   fiber.StatusInternalServerError,
+	errs.ErrUpdateTestcaseGeneration:   fiber.StatusInternalServerError,
+	errs.ErrGetTestcaseGeneration:      fiber.StatusInternalServerError,
+	errs.ErrTestcaseGenerationNotFound: fiber.StatusNotFound,
+	errs.ErrDupTestcaseGeneration:      fiber.StatusConflict,
+
+	errs.ErrInvalidTestcaseArchive: fiber.StatusBadRequest,
+	errs.ErrExportTestcase:         fiber.StatusInternalServerError,
+
+	errs.ErrGetLanguage:      fiber.StatusInternalServerError,
+	errs.ErrListLanguage:     fiber.StatusInternalServerError,
+	errs.ErrLanguageNotFound: fiber.StatusNotFound,
+	errs.ErrLanguageDisabled: fiber.StatusBadRequest,
+	errs.ErrUpdateLanguage:   fiber.StatusInternalServerError,
+
+	errs.ErrUpdateExtension:   fiber.StatusInternalServerError,
+	errs.ErrDeleteExtension:   fiber.StatusInternalServerError,
+	errs.ErrListExtension:     fiber.StatusInternalServerError,
+	errs.ErrExtensionNotFound: fiber.StatusNotFound,
+	errs.ErrExtensionNoUser:   fiber.StatusBadRequest,
+
+	errs.ErrUpdateTemplate:   fiber.StatusInternalServerError,
+	errs.ErrDeleteTemplate:   fiber.StatusInternalServerError,
+	errs.ErrListTemplate:     fiber.StatusInternalServerError,
+	errs.ErrTemplateNotFound: fiber.StatusNotFound,
+
+	errs.ErrCreateSimilarityJob:    fiber.StatusInternalServerError,
+	errs.ErrUpdateSimilarityJob:    fiber.StatusInternalServerError,
+	errs.ErrGetSimilarityJob:       fiber.StatusInternalServerError,
+	errs.ErrSimilarityJobNotFound:  fiber.StatusNotFound,
+	errs.ErrDupSimilarityJob:       fiber.StatusConflict,
+	errs.ErrGetSimilarityPair:      fiber.StatusInternalServerError,
+	errs.ErrListSimilarityPair:     fiber.StatusInternalServerError,
+	errs.ErrSimilarityPairNotFound: fiber.StatusNotFound,
+
 	errs.ErrCreateSurvey: fiber.StatusInternalServerError,
 }
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/codern-org/codern/domain"
 	"github.com/codern-org/codern/platform"
@@ -21,9 +22,9 @@ func NewAssignmentRepository(db *platform.MySql) domain.AssignmentRepository {
 func (r *assignmentRepository) Create(assignment *domain.Assignment) error {
 	_, err := r.db.NamedExec(`
 		INSERT INTO assignment
-			(id, workspace_id, name, description, detail_url, memory_limit, time_limit, level, publish_date, due_date)
+			(id, workspace_id, name, description, detail_url, memory_limit, time_limit, level, max_score, grading_priority, publish_date, due_date, late_policy, late_penalty, late_penalty_interval, late_grace_period, max_attempt, submission_interval, checker_type, checker_epsilon, checker_language, checker_file_url)
 		VALUES
-			(:id, :workspace_id, :name, :description, :detail_url, :memory_limit, :time_limit, :level, :publish_date, :due_date)
+			(:id, :workspace_id, :name, :description, :detail_url, :memory_limit, :time_limit, :level, :max_score, :grading_priority, :publish_date, :due_date, :late_policy, :late_penalty, :late_penalty_interval, :late_grace_period, :max_attempt, :submission_interval, :checker_type, :checker_epsilon, :checker_language, :checker_file_url)
 		`, assignment)
 	if err != nil {
 		return fmt.Errorf("cannot query to insert assignment: %w", err)
@@ -41,8 +42,20 @@ func (r *assignmentRepository) Update(assignment *domain.Assignment) error {
 			memory_limit = :memory_limit,
 			time_limit = :time_limit,
 			level = :level,
+			max_score = :max_score,
+			grading_priority = :grading_priority,
 			publish_date = :publish_date,
-			due_date = :due_date
+			due_date = :due_date,
+			late_policy = :late_policy,
+			late_penalty = :late_penalty,
+			late_penalty_interval = :late_penalty_interval,
+			late_grace_period = :late_grace_period,
+			max_attempt = :max_attempt,
+			submission_interval = :submission_interval,
+			checker_type = :checker_type,
+			checker_epsilon = :checker_epsilon,
+			checker_language = :checker_language,
+			checker_file_url = :checker_file_url
 		WHERE id = :id
 	`, assignment)
 
@@ -62,34 +75,166 @@ func (r *assignmentRepository) Delete(id int) error {
 }
 
 func (r *assignmentRepository) CreateTestcases(testcases []domain.Testcase) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		return r.createTestcases(tx, testcases)
+	})
+}
+
+func (r *assignmentRepository) createTestcases(tx *sqlx.Tx, testcases []domain.Testcase) error {
+	query := "INSERT INTO testcase (id, assignment_id, revision, testcase_group, weight, input_file_url, output_file_url, visibility) VALUES "
+	args := make([]interface{}, 0, len(testcases)*8)
+	for _, testcase := range testcases {
+		query += "(?, ?, ?, ?, ?, ?, ?, ?),"
+		args = append(
+			args,
+			testcase.Id, testcase.AssignmentId, testcase.Revision, testcase.Group, testcase.Weight,
+			testcase.InputFileUrl, testcase.OutputFileUrl, testcase.Visibility,
+		)
+	}
+
+	query = query[:len(query)-1]
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("cannot query to create testcase: %w", err)
+	}
+
+	return nil
+}
+
+func (r *assignmentRepository) CreateTestcaseGeneration(
+	generation *domain.TestcaseGeneration,
+	testcases []domain.Testcase,
+	outbox *domain.GradingOutbox,
+) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExec(`
+			INSERT INTO testcase_generation (id, assignment_id, revision, language, status, attempt)
+			VALUES (:id, :assignment_id, :revision, :language, :status, :attempt)
+		`, generation)
+		if err != nil {
+			return fmt.Errorf("cannot query to create testcase generation: %w", err)
+		}
+		if err := r.createTestcases(tx, testcases); err != nil {
+			return err
+		}
+		return r.createOutbox(tx, outbox)
+	})
+}
+
+func (r *assignmentRepository) RequeueTestcaseGeneration(
+	generation *domain.TestcaseGeneration,
+	outbox *domain.GradingOutbox,
+) (bool, error) {
+	isRequeued := false
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		// A generation finished meanwhile is not requested again
+		result, err := tx.Exec(
+			"UPDATE testcase_generation SET attempt = ?, requested_at = NOW() WHERE id = ? AND status = ?",
+			generation.Attempt, generation.Id, domain.GenerationStatusGenerating,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query to requeue testcase generation: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get affected rows of requeued testcase generation: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+		isRequeued = true
+		return r.createOutbox(tx, outbox)
+	})
+	return isRequeued, err
+}
+
+func (r *assignmentRepository) UpdateTestcaseGeneration(
+	id int,
+	status domain.GenerationStatus,
+	log *string,
+) (bool, error) {
+	// Only a running generation can be finished to not apply a redelivered result twice
+	result, err := r.db.Exec(
+		"UPDATE testcase_generation SET status = ?, log = ? WHERE id = ? AND status = ?",
+		status, log, id, domain.GenerationStatusGenerating,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to update testcase generation: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of testcase generation: %w", err)
+	}
+	return affected > 0, nil
+}
+
+func (r *assignmentRepository) GetTestcaseGeneration(id int) (*domain.TestcaseGeneration, error) {
+	var generation domain.TestcaseGeneration
+	err := r.db.Get(&generation, "SELECT * FROM testcase_generation WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get testcase generation: %w", err)
+	}
+	return &generation, nil
+}
+
+func (r *assignmentRepository) GetLatestTestcaseGeneration(assignmentId int) (*domain.TestcaseGeneration, error) {
+	var generation domain.TestcaseGeneration
+	err := r.db.Get(
+		&generation,
+		"SELECT * FROM testcase_generation WHERE assignment_id = ? ORDER BY revision DESC LIMIT 1",
+		assignmentId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get latest testcase generation: %w", err)
+	}
+	return &generation, nil
+}
+
+func (r *assignmentRepository) GetTestcaseRevision(assignmentId int) (int, error) {
 	var revision int
 	err := r.db.Get(
 		&revision,
 		"SELECT MAX(revision) AS revision FROM testcase WHERE assignment_id = ? GROUP BY assignment_id",
-		testcases[0].AssignmentId,
+		assignmentId,
 	)
 	if err == sql.ErrNoRows {
-		revision = 1
+		return 0, nil
 	} else if err != nil {
-		return fmt.Errorf("cannot query revision to create testcase: %w", err)
-	} else {
-		revision += 1
+		return 0, fmt.Errorf("cannot query to get testcase revision: %w", err)
 	}
+	return revision, nil
+}
 
-	query := "INSERT INTO testcase (id, assignment_id, revision, input_file_url, output_file_url) VALUES "
-	args := make([]interface{}, 0, len(testcases)*4)
-	for _, testcase := range testcases {
-		query += "(?, ?, ?, ?, ?),"
-		args = append(args, testcase.Id, testcase.AssignmentId, revision, testcase.InputFileUrl, testcase.OutputFileUrl)
+func (r *assignmentRepository) ListTestcase(assignmentId int, revision int) ([]domain.Testcase, error) {
+	testcases := make([]domain.Testcase, 0)
+	err := r.db.Select(
+		&testcases,
+		"SELECT * FROM testcase WHERE assignment_id = ? AND revision = ? ORDER BY id ASC",
+		assignmentId, revision,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list testcase by revision: %w", err)
 	}
+	return testcases, nil
+}
 
-	query = query[:len(query)-1]
-
-	if _, err := r.db.Exec(query, args...); err != nil {
-		return fmt.Errorf("cannot query to create testcase: %w", err)
+func (r *assignmentRepository) GetTestcaseByFileUrl(assignmentId int, fileUrl string) (*domain.Testcase, error) {
+	var testcase domain.Testcase
+	err := r.db.Get(
+		&testcase,
+		"SELECT * FROM testcase WHERE assignment_id = ? AND (input_file_url = ? OR output_file_url = ?) LIMIT 1",
+		assignmentId, fileUrl, fileUrl,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get testcase by file url: %w", err)
 	}
-
-	return nil
+	return &testcase, nil
 }
 
 func (r *assignmentRepository) DeleteTestcases(assignmentId int) error {
@@ -102,49 +247,309 @@ func (r *assignmentRepository) DeleteTestcases(assignmentId int) error {
 
 func (r *assignmentRepository) CreateSubmission(
 	submission *domain.Submission,
-	testcases []domain.Testcase,
+	outbox *domain.GradingOutbox,
+	checkQuota func(attemptCount int, lastSubmittedAt *time.Time) error,
 ) error {
-	_, err := r.db.NamedExec(`
-		INSERT INTO submission (id, assignment_id, user_id, language, status, score, file_url)
-		VALUES (:id, :assignment_id, :user_id, :language, 'GRADING', 0, :file_url)
-	`, submission)
-	if err != nil {
-		return fmt.Errorf("cannot query to create submission: %w", err)
-	}
-	return nil
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		if checkQuota != nil {
+			if err := r.checkSubmissionQuota(tx, submission, checkQuota); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.NamedExec(`
+			INSERT INTO submission (id, assignment_id, user_id, language, status, grading_attempt, grading_retry, testcase_revision, score, file_url, entry_point)
+			VALUES (:id, :assignment_id, :user_id, :language, 'GRADING', :grading_attempt, :grading_retry, :testcase_revision, 0, :file_url, :entry_point)
+		`, submission)
+		if err != nil {
+			return fmt.Errorf("cannot query to create submission: %w", err)
+		}
+
+		if len(submission.Files) > 0 {
+			_, err = tx.NamedExec(`
+				INSERT INTO submission_file (submission_id, path, file_url, size)
+				VALUES (:submission_id, :path, :file_url, :size)
+			`, submission.Files)
+			if err != nil {
+				return fmt.Errorf("cannot query to create submission file: %w", err)
+			}
+		}
+
+		return r.createOutbox(tx, outbox)
+	})
 }
 
 func (r *assignmentRepository) CreateSubmissionResults(
 	submissionId int,
+	attempt int,
 	compilationLog string,
 	status domain.AssignmentStatus,
+	rawScore float64,
 	score float64,
 	results []domain.SubmissionResult,
-) error {
-	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
-		_, err := tx.Exec(
-			"UPDATE submission SET compilation_log = ?, status = ?, score = ? WHERE id = ?",
-			compilationLog, status, score, submissionId,
+) (bool, error) {
+	isCreated := false
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		// Processed message key makes a redelivered grading response a no-op
+		processed, err := tx.Exec(
+			"INSERT IGNORE INTO grading_processed_message (submission_id, attempt) VALUES (?, ?)",
+			submissionId, attempt,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query to create processed grading message: %w", err)
+		}
+		if affected, err := processed.RowsAffected(); err != nil {
+			return fmt.Errorf("cannot get affected rows of processed grading message: %w", err)
+		} else if affected == 0 {
+			return nil
+		}
+
+		// Ignore the result of an outdated attempt to not overwrite a newer one
+		var currentAttempt int
+		err = tx.Get(&currentAttempt, "SELECT grading_attempt FROM submission WHERE id = ? FOR UPDATE", submissionId)
+		if err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("cannot query to get grading attempt of submission: %w", err)
+		} else if attempt < currentAttempt {
+			return nil
+		}
+
+		_, err = tx.Exec(
+			"UPDATE submission SET compilation_log = ?, status = ?, raw_score = ?, score = ? WHERE id = ?",
+			compilationLog, status, rawScore, score, submissionId,
 		)
 		if err != nil {
 			return fmt.Errorf("cannot query to update submission from submission result: %w", err)
 		}
 
-		query := "INSERT INTO submission_result (submission_id, testcase_id, is_passed, status, memory_usage, time_usage) VALUES "
-		for _, result := range results {
-			query += fmt.Sprintf(
-				"('%d', '%d', %t, '%s', '%d', '%d'),",
-				result.SubmissionId, result.TestcaseId, result.IsPassed, result.Status,
-				*result.MemoryUsage, *result.TimeUsage,
+		if _, err := tx.Exec("DELETE FROM submission_result WHERE submission_id = ?", submissionId); err != nil {
+			return fmt.Errorf("cannot query to delete previous submission result: %w", err)
+		}
+
+		if len(results) > 0 {
+			query := "INSERT INTO submission_result (submission_id, testcase_id, is_passed, status, memory_usage, time_usage) VALUES "
+			for _, result := range results {
+				query += fmt.Sprintf(
+					"('%d', '%d', %t, '%s', '%d', '%d'),",
+					result.SubmissionId, result.TestcaseId, result.IsPassed, result.Status,
+					*result.MemoryUsage, *result.TimeUsage,
+				)
+			}
+			query = query[:len(query)-1] // Remove trailing comma
+			if _, err := tx.Exec(query); err != nil {
+				return fmt.Errorf("cannot query to create submission result: %w", err)
+			}
+		}
+
+		isCreated = true
+		return nil
+	})
+	return isCreated, err
+}
+
+func (r *assignmentRepository) RequeueSubmission(
+	submission *domain.Submission,
+	outbox *domain.GradingOutbox,
+) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		return r.requeueSubmission(tx, submission, outbox)
+	})
+}
+
+func (r *assignmentRepository) requeueSubmission(
+	tx *sqlx.Tx,
+	submission *domain.Submission,
+	outbox *domain.GradingOutbox,
+) error {
+	_, err := tx.Exec(`
+		UPDATE submission SET
+			status = 'GRADING',
+			grading_attempt = ?,
+			grading_retry = ?,
+			grading_requested_at = NOW(),
+			testcase_revision = ?
+		WHERE id = ?
+	`, submission.GradingAttempt, submission.GradingRetry, submission.TestcaseRevision, submission.Id)
+	if err != nil {
+		return fmt.Errorf("cannot query to requeue submission: %w", err)
+	}
+
+	return r.createOutbox(tx, outbox)
+}
+
+func (r *assignmentRepository) checkSubmissionQuota(
+	tx *sqlx.Tx,
+	submission *domain.Submission,
+	checkQuota func(attemptCount int, lastSubmittedAt *time.Time) error,
+) error {
+	// Locking the submitter serializes concurrent submissions to not exceed the quota together
+	if _, err := tx.Exec("SELECT id FROM user WHERE id = ? FOR UPDATE", submission.SubmitterId); err != nil {
+		return fmt.Errorf("cannot query to lock submitter: %w", err)
+	}
+
+	var quota struct {
+		AttemptCount    int        `db:"attempt_count"`
+		LastSubmittedAt *time.Time `db:"last_submitted_at"`
+	}
+	err := tx.Get(&quota, `
+		SELECT
+			IFNULL(SUM(CASE WHEN status != 'SYSTEM_FAILURE' THEN 1 ELSE 0 END), 0) AS attempt_count,
+			MAX(submitted_at) AS last_submitted_at
+		FROM submission
+		WHERE assignment_id = ? AND user_id = ?
+	`, submission.AssignmentId, submission.SubmitterId)
+	if err != nil {
+		return fmt.Errorf("cannot query to get submission quota: %w", err)
+	}
+
+	return checkQuota(quota.AttemptCount, quota.LastSubmittedAt)
+}
+
+func (r *assignmentRepository) createOutbox(tx *sqlx.Tx, outbox *domain.GradingOutbox) error {
+	_, err := tx.NamedExec(`
+		INSERT INTO grading_outbox (id, submission_id, generation_id, exchange, routing_key, body, priority)
+		VALUES (:id, :submission_id, :generation_id, :exchange, :routing_key, :body, :priority)
+	`, outbox)
+	if err != nil {
+		return fmt.Errorf("cannot query to create grading outbox: %w", err)
+	}
+	return nil
+}
+
+func (r *assignmentRepository) CreateRegradeJob(
+	job *domain.RegradeJob,
+	submissions []domain.Submission,
+	outboxes []domain.GradingOutbox,
+) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExec(`
+			INSERT INTO regrade_job (id, assignment_id, requester_id, testcase_revision, total)
+			VALUES (:id, :assignment_id, :requester_id, :testcase_revision, :total)
+		`, job)
+		if err != nil {
+			return fmt.Errorf("cannot query to create regrade job: %w", err)
+		}
+
+		for i := range submissions {
+			_, err := tx.Exec(
+				"INSERT INTO regrade_job_submission (job_id, submission_id) VALUES (?, ?)",
+				job.Id, submissions[i].Id,
 			)
+			if err != nil {
+				return fmt.Errorf("cannot query to create regrade job submission: %w", err)
+			}
+			if err := r.requeueSubmission(tx, &submissions[i], &outboxes[i]); err != nil {
+				return err
+			}
 		}
-		query = query[:len(query)-1] // Remove trailing comma
-		if _, err := tx.Exec(query); err != nil {
-			return fmt.Errorf("cannot query to create submission result: %w", err)
+
+		return nil
+	})
+}
+
+func (r *assignmentRepository) CreateSimilarityJob(job *domain.SimilarityJob, timeout time.Duration) (bool, error) {
+	isCreated := false
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		// Locking the assignment serializes concurrent requests to not start two jobs together
+		if _, err := tx.Exec("SELECT id FROM assignment WHERE id = ? FOR UPDATE", job.AssignmentId); err != nil {
+			return fmt.Errorf("cannot query to lock assignment: %w", err)
 		}
 
+		var runningCount int
+		err := tx.Get(&runningCount, `
+			SELECT COUNT(*) FROM similarity_job
+			WHERE assignment_id = ? AND status = ? AND created_at > DATE_SUB(NOW(), INTERVAL ? SECOND)
+		`, job.AssignmentId, domain.SimilarityStatusRunning, int(timeout.Seconds()))
+		if err != nil {
+			return fmt.Errorf("cannot query to count running similarity job: %w", err)
+		}
+		if runningCount > 0 {
+			return nil
+		}
+
+		_, err = tx.NamedExec(`
+			INSERT INTO similarity_job (id, assignment_id, requester_id, status, total)
+			VALUES (:id, :assignment_id, :requester_id, :status, :total)
+		`, job)
+		if err != nil {
+			return fmt.Errorf("cannot query to create similarity job: %w", err)
+		}
+		isCreated = true
+		return nil
+	})
+	return isCreated, err
+}
+
+func (r *assignmentRepository) UpdateSimilarityJob(
+	id int,
+	status domain.SimilarityStatus,
+	log *string,
+	pairs []domain.SimilarityPair,
+) (bool, error) {
+	isUpdated := false
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		// Only a running job can be finished to not store the pairs of a timed out job twice
+		result, err := tx.Exec(
+			"UPDATE similarity_job SET status = ?, log = ? WHERE id = ? AND status = ?",
+			status, log, id, domain.SimilarityStatusRunning,
+		)
+		if err != nil {
+			return fmt.Errorf("cannot query to update similarity job: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("cannot get affected rows of similarity job: %w", err)
+		}
+		if affected == 0 {
+			return nil
+		}
+		isUpdated = true
+
+		for start := 0; start < len(pairs); start += similarityPairBatchSize {
+			end := min(start+similarityPairBatchSize, len(pairs))
+			if err := r.createSimilarityPairs(tx, pairs[start:end]); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
+	return isUpdated, err
+}
+
+// Keeps the placeholders of a query within the limit of MySQL
+const similarityPairBatchSize = 1000
+
+func (r *assignmentRepository) createSimilarityPairs(tx *sqlx.Tx, pairs []domain.SimilarityPair) error {
+	query := "INSERT INTO similarity_pair (job_id, submission_id, other_submission_id, score) VALUES "
+	args := make([]interface{}, 0, len(pairs)*4)
+	for _, pair := range pairs {
+		query += "(?, ?, ?, ?),"
+		args = append(args, pair.JobId, pair.SubmissionId, pair.OtherSubmissionId, pair.Score)
+	}
+
+	query = query[:len(query)-1]
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("cannot query to create similarity pair: %w", err)
+	}
+
+	return nil
+}
+
+func (r *assignmentRepository) FailSubmission(id int) (bool, error) {
+	result, err := r.db.Exec(
+		"UPDATE submission SET status = ? WHERE id = ? AND status = 'GRADING'",
+		domain.AssignmentStatusSystemFailure, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to fail submission: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot get affected rows of failed submission: %w", err)
+	}
+	return affected > 0, nil
 }
 
 func (r *assignmentRepository) GetWithStatus(id int, userId string) (*domain.AssignmentWithStatus, error) {
@@ -185,7 +590,196 @@ func (r *assignmentRepository) GetSubmission(id int) (*domain.Submission, error)
 		return nil, fmt.Errorf("cannot query to list submission result: %w", err)
 	}
 	submission.Results = results
-	return &submission, nil
+
+	submissions := []domain.Submission{submission}
+	if err := r.mutateSubmissionFiles(submissions); err != nil {
+		return nil, err
+	}
+	return &submissions[0], nil
+}
+
+func (r *assignmentRepository) UpdateExtension(extension *domain.AssignmentExtension) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO assignment_extension (assignment_id, user_id, due_date, time_limit_multiplier)
+		VALUES (:assignment_id, :user_id, :due_date, :time_limit_multiplier)
+		ON DUPLICATE KEY UPDATE
+			due_date = VALUES(due_date),
+			time_limit_multiplier = VALUES(time_limit_multiplier)
+	`, extension)
+	if err != nil {
+		return fmt.Errorf("cannot query to update assignment extension: %w", err)
+	}
+	return nil
+}
+
+func (r *assignmentRepository) DeleteExtension(assignmentId int, userId string) error {
+	_, err := r.db.Exec(
+		"DELETE FROM assignment_extension WHERE assignment_id = ? AND user_id = ?",
+		assignmentId, userId,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete assignment extension: %w", err)
+	}
+	return nil
+}
+
+func (r *assignmentRepository) GetExtension(assignmentId int, userId string) (*domain.AssignmentExtension, error) {
+	var extension domain.AssignmentExtension
+	err := r.db.Get(
+		&extension,
+		"SELECT * FROM assignment_extension WHERE assignment_id = ? AND user_id = ?",
+		assignmentId, userId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get assignment extension: %w", err)
+	}
+	return &extension, nil
+}
+
+func (r *assignmentRepository) ListExtension(assignmentId int) ([]domain.AssignmentExtension, error) {
+	extensions := make([]domain.AssignmentExtension, 0)
+	err := r.db.Select(
+		&extensions,
+		"SELECT * FROM assignment_extension WHERE assignment_id = ? ORDER BY created_at ASC",
+		assignmentId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list assignment extension: %w", err)
+	}
+	return extensions, nil
+}
+
+func (r *assignmentRepository) UpdateTemplate(template *domain.AssignmentTemplate) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO assignment_template (assignment_id, language, type, file_url)
+		VALUES (:assignment_id, :language, :type, :file_url)
+		ON DUPLICATE KEY UPDATE file_url = VALUES(file_url)
+	`, template)
+	if err != nil {
+		return fmt.Errorf("cannot query to update assignment template: %w", err)
+	}
+	return nil
+}
+
+func (r *assignmentRepository) DeleteTemplate(
+	assignmentId int,
+	language string,
+	templateType domain.TemplateType,
+) error {
+	_, err := r.db.Exec(
+		"DELETE FROM assignment_template WHERE assignment_id = ? AND language = ? AND type = ?",
+		assignmentId, language, templateType,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to delete assignment template: %w", err)
+	}
+	return nil
+}
+
+func (r *assignmentRepository) GetTemplate(
+	assignmentId int,
+	language string,
+	templateType domain.TemplateType,
+) (*domain.AssignmentTemplate, error) {
+	var template domain.AssignmentTemplate
+	err := r.db.Get(
+		&template,
+		"SELECT * FROM assignment_template WHERE assignment_id = ? AND language = ? AND type = ?",
+		assignmentId, language, templateType,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get assignment template: %w", err)
+	}
+	return &template, nil
+}
+
+func (r *assignmentRepository) ListTemplate(assignmentId int) ([]domain.AssignmentTemplate, error) {
+	templates := make([]domain.AssignmentTemplate, 0)
+	err := r.db.Select(
+		&templates,
+		"SELECT * FROM assignment_template WHERE assignment_id = ? ORDER BY language ASC, type ASC",
+		assignmentId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list assignment template: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *assignmentRepository) GetRegradeJob(id int) (*domain.RegradeJob, error) {
+	var job domain.RegradeJob
+	err := r.db.Get(&job, `
+		SELECT
+			j.*,
+			COUNT(CASE WHEN s.status IN ('COMPLETED', 'INCOMPLETED') THEN 1 END) AS completed,
+			COUNT(CASE WHEN s.status = 'SYSTEM_FAILURE' THEN 1 END) AS failed
+		FROM regrade_job j
+		LEFT JOIN regrade_job_submission js ON js.job_id = j.id
+		LEFT JOIN submission s ON s.id = js.submission_id
+		WHERE j.id = ?
+		GROUP BY j.id
+	`, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get regrade job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *assignmentRepository) GetSimilarityJob(id int) (*domain.SimilarityJob, error) {
+	var job domain.SimilarityJob
+	err := r.db.Get(&job, "SELECT * FROM similarity_job WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get similarity job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *assignmentRepository) GetLatestSimilarityJob(assignmentId int) (*domain.SimilarityJob, error) {
+	var job domain.SimilarityJob
+	err := r.db.Get(
+		&job,
+		"SELECT * FROM similarity_job WHERE assignment_id = ? ORDER BY created_at DESC, id DESC LIMIT 1",
+		assignmentId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get latest similarity job: %w", err)
+	}
+	return &job, nil
+}
+
+// GetSimilarityPair gets a pair regardless of the order of its submissions
+func (r *assignmentRepository) GetSimilarityPair(
+	jobId int,
+	submissionId int,
+	otherSubmissionId int,
+) (*domain.SimilarityPair, error) {
+	var pair domain.SimilarityPair
+	err := r.db.Get(&pair, `
+		SELECT p.*, s.user_id, os.user_id AS other_user_id
+		FROM similarity_pair p
+		INNER JOIN submission s ON s.id = p.submission_id
+		INNER JOIN submission os ON os.id = p.other_submission_id
+		WHERE p.job_id = ? AND (
+			(p.submission_id = ? AND p.other_submission_id = ?)
+			OR (p.submission_id = ? AND p.other_submission_id = ?)
+		)
+	`, jobId, submissionId, otherSubmissionId, otherSubmissionId, submissionId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get similarity pair: %w", err)
+	}
+	return &pair, nil
 }
 
 func (r *assignmentRepository) List(userId string, workspaceId int) ([]domain.AssignmentWithStatus, error) {
@@ -202,8 +796,10 @@ func (r *assignmentRepository) list(
 	query := `
 		SELECT
 			a.*,
+			wl.score AS level_score,
 			t1.last_submitted_at,
 			IFNULL(t1.status, 'TODO') AS status,
+			IFNULL(t1.attempt_count, 0) AS attempt_count,
 			t1.score
 		FROM (
 			SELECT
@@ -214,12 +810,14 @@ func (r *assignmentRepository) list(
 					WHEN SUM(CASE WHEN s.status = 'COMPLETED' THEN 1 ELSE 0 END) > 0 THEN 'COMPLETED'
 					ELSE 'INCOMPLETED'
 				END AS status,
-				MAX(s.score) AS score
+				MAX(s.score) AS score,
+				SUM(CASE WHEN s.status != 'SYSTEM_FAILURE' THEN 1 ELSE 0 END) AS attempt_count
 			FROM submission s
 			WHERE s.user_id = ? AND s.assignment_id %[1]s
 			GROUP BY s.assignment_id
 		) t1
 		RIGHT JOIN assignment a ON a.id = t1.assignment_id
+		LEFT JOIN workspace_level wl ON wl.workspace_id = a.workspace_id AND wl.level = a.level
 		WHERE a.id %[1]s
 	`
 
@@ -243,6 +841,9 @@ func (r *assignmentRepository) list(
 		if err := r.mutateTestcases(params); err != nil {
 			return nil, err
 		}
+		if err := r.mutateExtensions(userId, assignments); err != nil {
+			return nil, err
+		}
 	}
 
 	return assignments, nil
@@ -254,15 +855,21 @@ func (r *assignmentRepository) listRaw(
 ) ([]domain.Assignment, error) {
 	rawAssignments := make([]domain.Assignment, 0)
 
+	query := `
+		SELECT a.*, wl.score AS level_score
+		FROM assignment a
+		LEFT JOIN workspace_level wl ON wl.workspace_id = a.workspace_id AND wl.level = a.level
+	`
+
 	if workspaceId != nil {
-		query := `SELECT * FROM assignment WHERE workspace_id = ? AND is_deleted = FALSE`
+		query := query + "WHERE a.workspace_id = ? AND a.is_deleted = FALSE"
 		if err := r.db.Select(&rawAssignments, query, workspaceId); err != nil {
 			return nil, fmt.Errorf("cannot query to list raw assignment with workspace id: %w", err)
 		}
 	}
 
 	if assignmentId != nil {
-		query := `SELECT * FROM assignment WHERE id = ? AND is_deleted = FALSE`
+		query := query + "WHERE a.id = ? AND a.is_deleted = FALSE"
 		if err := r.db.Select(&rawAssignments, query, assignmentId); err != nil {
 			return nil, fmt.Errorf("cannot query to list raw assignment with id: %w", err)
 		}
@@ -303,14 +910,47 @@ func (r *assignmentRepository) mutateTestcases(assignments []*domain.Assignment)
 	return nil
 }
 
+// mutateExtensions applies the extensions granted to the user onto the assignments
+func (r *assignmentRepository) mutateExtensions(userId string, assignments []domain.AssignmentWithStatus) error {
+	var assignmentIds []int
+	for i := range assignments {
+		assignmentIds = append(assignmentIds, assignments[i].Id)
+	}
+
+	var extensions []domain.AssignmentExtension
+	query, args, err := sqlx.In(
+		"SELECT * FROM assignment_extension WHERE user_id = ? AND assignment_id IN (?)",
+		userId, assignmentIds,
+	)
+	if err != nil {
+		return fmt.Errorf("cannot query to create query to list assignment extension: %w", err)
+	}
+	if err = r.db.Select(&extensions, query, args...); err != nil {
+		return fmt.Errorf("cannot query to list assignment extension for assignment: %w", err)
+	}
+
+	assignmentById := make(map[int]*domain.AssignmentWithStatus)
+	for i := range assignments {
+		assignmentById[assignments[i].Id] = &assignments[i]
+	}
+	for i := range extensions {
+		assignment := assignmentById[extensions[i].AssignmentId]
+		assignment.Extension = &extensions[i]
+		assignment.ApplyExtension(&extensions[i])
+	}
+
+	return nil
+}
+
 func (r *assignmentRepository) listTestcase(assignmentIds []int) ([]domain.Testcase, error) {
 	var testcases []domain.Testcase
 	query, args, err := sqlx.In(`
 		WITH assignment_latest_revision AS (
-			SELECT assignment_id, MAX(revision) AS lastet_revision
-			FROM testcase
-			WHERE assignment_id IN (?)
-			GROUP BY assignment_id
+			SELECT t.assignment_id, MAX(t.revision) AS lastet_revision
+			FROM testcase t
+			LEFT JOIN testcase_generation g ON g.assignment_id = t.assignment_id AND g.revision = t.revision
+			WHERE t.assignment_id IN (?) AND (g.id IS NULL OR g.status = 'COMPLETED')
+			GROUP BY t.assignment_id
 		)
 		SELECT testcase.*
 		FROM assignment_latest_revision t1
@@ -351,14 +991,15 @@ func (r *assignmentRepository) ListSubmission(
 			u.display_name AS user_display_name,
 			u.profile_url AS user_profile_url,
 			CASE
-				WHEN s.submitted_at > a.due_date THEN TRUE
-				WHEN s.submitted_at < a.due_date THEN FALSE
-				WHEN s.submitted_at = a.due_date THEN FALSE
+				WHEN s.submitted_at > COALESCE(e.due_date, a.due_date) THEN TRUE
+				WHEN s.submitted_at < COALESCE(e.due_date, a.due_date) THEN FALSE
+				WHEN s.submitted_at = COALESCE(e.due_date, a.due_date) THEN FALSE
 				ELSE FALSE
 			END AS is_late
 		FROM submission s
 		INNER JOIN user u ON u.id = s.user_id
 		INNER JOIN assignment a ON a.id = s.assignment_id
+		LEFT JOIN assignment_extension e ON e.assignment_id = s.assignment_id AND e.user_id = s.user_id
 		%s
 	`, whereQueryString)
 
@@ -389,7 +1030,145 @@ func (r *assignmentRepository) ListSubmission(
 			submission := submissionById[results[i].SubmissionId]
 			submission.Results = append(submission.Results, results[i])
 		}
+
+		if err := r.mutateSubmissionFiles(submissions); err != nil {
+			return nil, err
+		}
 	}
 
 	return submissions, nil
 }
+
+func (r *assignmentRepository) mutateSubmissionFiles(submissions []domain.Submission) error {
+	var submissionIds []int
+	for i := range submissions {
+		submissionIds = append(submissionIds, submissions[i].Id)
+	}
+
+	var files []domain.SubmissionFile
+	query, args, err := sqlx.In("SELECT * FROM submission_file WHERE submission_id IN (?) ORDER BY path ASC", submissionIds)
+	if err != nil {
+		return fmt.Errorf("cannot query to create query to list submission file: %w", err)
+	}
+	if err = r.db.Select(&files, query, args...); err != nil {
+		return fmt.Errorf("cannot query to list submission file: %w", err)
+	}
+
+	submissionById := make(map[int]*domain.Submission)
+	for i := range submissions {
+		submissionById[submissions[i].Id] = &submissions[i]
+	}
+	for i := range files {
+		submission := submissionById[files[i].SubmissionId]
+		submission.Files = append(submission.Files, files[i])
+	}
+	return nil
+}
+
+func (r *assignmentRepository) ListStuckSubmission(timeout time.Duration) ([]domain.Submission, error) {
+	submissions := make([]domain.Submission, 0)
+	// A request not published yet is still retried by the outbox relay, so the timeout starts
+	// once the latest request is published, submissions without an outbox are timed from the request
+	err := r.db.Select(&submissions, `
+		SELECT s.* FROM submission s
+		WHERE
+			s.status = 'GRADING'
+			AND NOT EXISTS (
+				SELECT 1 FROM grading_outbox o WHERE o.submission_id = s.id AND o.sent_at IS NULL
+			)
+			AND IFNULL(
+				(SELECT MAX(o.sent_at) FROM grading_outbox o WHERE o.submission_id = s.id),
+				s.grading_requested_at
+			) < DATE_SUB(NOW(), INTERVAL ? SECOND)
+		ORDER BY s.grading_requested_at ASC
+	`, int(timeout.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list stuck submission: %w", err)
+	}
+	if len(submissions) > 0 {
+		if err := r.mutateSubmissionFiles(submissions); err != nil {
+			return nil, err
+		}
+	}
+	return submissions, nil
+}
+
+func (r *assignmentRepository) ListStuckTestcaseGeneration(timeout time.Duration) ([]domain.TestcaseGeneration, error) {
+	generations := make([]domain.TestcaseGeneration, 0)
+	// The timeout starts once the latest request is published as for submissions
+	err := r.db.Select(&generations, `
+		SELECT g.* FROM testcase_generation g
+		WHERE
+			g.status = ?
+			AND NOT EXISTS (
+				SELECT 1 FROM grading_outbox o WHERE o.generation_id = g.id AND o.sent_at IS NULL
+			)
+			AND IFNULL(
+				(SELECT MAX(o.sent_at) FROM grading_outbox o WHERE o.generation_id = g.id),
+				g.requested_at
+			) < DATE_SUB(NOW(), INTERVAL ? SECOND)
+		ORDER BY g.requested_at ASC
+	`, domain.GenerationStatusGenerating, int(timeout.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list stuck testcase generation: %w", err)
+	}
+	return generations, nil
+}
+
+func (r *assignmentRepository) ListRegradeSubmission(
+	assignmentId int,
+	filter *domain.RegradeFilter,
+) ([]domain.Submission, error) {
+	submissions := make([]domain.Submission, 0)
+
+	queryArgs := []interface{}{assignmentId}
+	whereQueries := []string{"s.assignment_id = ?"}
+
+	if filter.IsLatestOnly {
+		whereQueries = append(whereQueries, `s.submitted_at = (
+			SELECT MAX(submitted_at) FROM submission
+			WHERE assignment_id = s.assignment_id AND user_id = s.user_id
+		)`)
+	}
+	if len(filter.UserIds) > 0 {
+		queryArgs = append(queryArgs, filter.UserIds)
+		whereQueries = append(whereQueries, "s.user_id IN (?)")
+	}
+	if filter.Status != nil {
+		queryArgs = append(queryArgs, *filter.Status)
+		whereQueries = append(whereQueries, "s.status = ?")
+	}
+
+	query, args, err := sqlx.In(
+		fmt.Sprintf("SELECT s.* FROM submission s WHERE %s", strings.Join(whereQueries, " AND ")),
+		queryArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to create query to list regrade submission: %w", err)
+	}
+	if err := r.db.Select(&submissions, query, args...); err != nil {
+		return nil, fmt.Errorf("cannot query to list regrade submission: %w", err)
+	}
+	if len(submissions) > 0 {
+		if err := r.mutateSubmissionFiles(submissions); err != nil {
+			return nil, err
+		}
+	}
+	return submissions, nil
+}
+
+func (r *assignmentRepository) ListSimilarityPair(jobId int) ([]domain.SimilarityPair, error) {
+	pairs := make([]domain.SimilarityPair, 0)
+	err := r.db.Select(&pairs, `
+		SELECT p.*, s.user_id, os.user_id AS other_user_id
+		FROM similarity_pair p
+		INNER JOIN submission s ON s.id = p.submission_id
+		INNER JOIN submission os ON os.id = p.other_submission_id
+		WHERE p.job_id = ?
+		ORDER BY p.score DESC
+	`, jobId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list similarity pair: %w", err)
+	}
+	return pairs, nil
+}
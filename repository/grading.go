@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+	"github.com/jmoiron/sqlx"
+)
+
+type gradingRepository struct {
+	db *platform.MySql
+}
+
+func NewGradingRepository(db *platform.MySql) domain.GradingRepository {
+	return &gradingRepository{db: db}
+}
+
+func (r *gradingRepository) ProcessOutbox(
+	limit int,
+	maxBackoff time.Duration,
+	fn func(outbox *domain.GradingOutbox) error,
+) error {
+	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		outboxes := make([]domain.GradingOutbox, 0)
+		err := tx.Select(&outboxes, `
+			SELECT * FROM grading_outbox
+			WHERE sent_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY created_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		`, limit)
+		if err != nil {
+			return fmt.Errorf("cannot query to list pending grading outbox: %w", err)
+		}
+
+		for i := range outboxes {
+			if _, err := r.sendOutbox(tx, &outboxes[i], maxBackoff, fn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *gradingRepository) SendOutbox(
+	id int,
+	maxBackoff time.Duration,
+	fn func(outbox *domain.GradingOutbox) error,
+) (bool, error) {
+	isSent := false
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		outboxes := make([]domain.GradingOutbox, 0)
+		err := tx.Select(&outboxes, `
+			SELECT * FROM grading_outbox
+			WHERE id = ?
+			FOR UPDATE SKIP LOCKED
+		`, id)
+		if err != nil {
+			return fmt.Errorf("cannot query to get pending grading outbox: %w", err)
+		}
+
+		// The message is being sent by the relay
+		if len(outboxes) == 0 {
+			return nil
+		}
+		if outboxes[0].SentAt != nil {
+			isSent = true
+			return nil
+		}
+
+		isSent, err = r.sendOutbox(tx, &outboxes[0], maxBackoff, fn)
+		return err
+	})
+	return isSent, err
+}
+
+func (r *gradingRepository) sendOutbox(
+	tx *sqlx.Tx,
+	outbox *domain.GradingOutbox,
+	maxBackoff time.Duration,
+	fn func(outbox *domain.GradingOutbox) error,
+) (bool, error) {
+	if err := fn(outbox); err != nil {
+		_, err = tx.Exec(`
+			UPDATE grading_outbox SET
+				attempt = attempt + 1,
+				last_error = ?,
+				next_attempt_at = DATE_ADD(NOW(), INTERVAL LEAST(POW(2, attempt), ?) SECOND)
+			WHERE id = ?
+		`, err.Error(), int(maxBackoff.Seconds()), outbox.Id)
+		if err != nil {
+			return false, fmt.Errorf("cannot query to update failed grading outbox: %w", err)
+		}
+		return false, nil
+	}
+
+	_, err := tx.Exec(
+		"UPDATE grading_outbox SET attempt = attempt + 1, last_error = NULL, sent_at = NOW() WHERE id = ?",
+		outbox.Id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("cannot query to update sent grading outbox: %w", err)
+	}
+	return true, nil
+}
+
+func (r *gradingRepository) GetLatestOutbox(submissionId int) (*domain.GradingOutbox, error) {
+	var outbox domain.GradingOutbox
+	err := r.db.Get(
+		&outbox,
+		"SELECT * FROM grading_outbox WHERE submission_id = ? ORDER BY created_at DESC, id DESC LIMIT 1",
+		submissionId,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get latest grading outbox: %w", err)
+	}
+	return &outbox, nil
+}
+
+func (r *gradingRepository) CreateDeadLetter(deadLetter *domain.DeadLetter) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO grading_dead_letter (id, queue, reason, body)
+		VALUES (:id, :queue, :reason, :body)
+	`, deadLetter)
+	if err != nil {
+		return fmt.Errorf("cannot query to create grading dead letter: %w", err)
+	}
+	return nil
+}
+
+func (r *gradingRepository) GetDeadLetter(id int) (*domain.DeadLetter, error) {
+	var deadLetter domain.DeadLetter
+	err := r.db.Get(&deadLetter, "SELECT * FROM grading_dead_letter WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get grading dead letter: %w", err)
+	}
+	return &deadLetter, nil
+}
+
+func (r *gradingRepository) ListDeadLetter() ([]domain.DeadLetter, error) {
+	deadLetters := make([]domain.DeadLetter, 0)
+	err := r.db.Select(&deadLetters, "SELECT * FROM grading_dead_letter ORDER BY created_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list grading dead letter: %w", err)
+	}
+	return deadLetters, nil
+}
+
+func (r *gradingRepository) UpdateDeadLetterReplayed(id int) error {
+	_, err := r.db.Exec("UPDATE grading_dead_letter SET replayed_at = NOW() WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("cannot query to update replayed grading dead letter: %w", err)
+	}
+	return nil
+}
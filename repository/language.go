@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/codern-org/codern/domain"
+	"github.com/codern-org/codern/platform"
+)
+
+type languageRepository struct {
+	db *platform.MySql
+}
+
+func NewLanguageRepository(db *platform.MySql) domain.LanguageRepository {
+	return &languageRepository{db: db}
+}
+
+// A language is enabled in a workspace only if it is enabled globally and not disabled by the workspace
+const workspaceLanguageQuery = `
+	SELECT
+		l.id, l.name, l.compiler_version, l.file_extension, l.routing_key,
+		l.is_enabled AND COALESCE(wl.is_enabled, TRUE) AS is_enabled
+	FROM language l
+	LEFT JOIN workspace_language wl ON wl.language_id = l.id AND wl.workspace_id = ?
+`
+
+func (r *languageRepository) Get(id string, workspaceId int) (*domain.Language, error) {
+	var language domain.Language
+	err := r.db.Get(&language, workspaceLanguageQuery+"WHERE l.id = ?", workspaceId, id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot query to get language: %w", err)
+	}
+	return &language, nil
+}
+
+func (r *languageRepository) List() ([]domain.Language, error) {
+	languages := make([]domain.Language, 0)
+	err := r.db.Select(&languages, "SELECT * FROM language ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list language: %w", err)
+	}
+	return languages, nil
+}
+
+func (r *languageRepository) ListByWorkspace(workspaceId int) ([]domain.Language, error) {
+	languages := make([]domain.Language, 0)
+	err := r.db.Select(&languages, workspaceLanguageQuery+"ORDER BY l.name ASC", workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace language: %w", err)
+	}
+	return languages, nil
+}
+
+func (r *languageRepository) UpdateWorkspaceLanguage(workspaceId int, languageId string, isEnabled bool) error {
+	_, err := r.db.Exec(`
+		INSERT INTO workspace_language (workspace_id, language_id, is_enabled)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE is_enabled = VALUES(is_enabled)
+	`, workspaceId, languageId, isEnabled)
+	if err != nil {
+		return fmt.Errorf("cannot query to update workspace language: %w", err)
+	}
+	return nil
+}
@@ -172,22 +172,20 @@ func (r *workspaceRepository) GetScoreboard(workspaceId int) ([]domain.Workspace
 	scoreboard := make([]domain.WorkspaceRank, 0)
 	err := r.db.Select(&scoreboard, `
 		WITH filtered_submission AS (
-			SELECT *
-			FROM (
-				SELECT
-					*,
-					COALESCE(
-						(SELECT assignment.due_date FROM assignment WHERE assignment.id = submission.assignment_id),
-						'9999-01-01 00:00:00'
-					) as due_date
-				FROM submission
-				WHERE
-					assignment_id IN (SELECT id FROM assignment WHERE workspace_id = ? AND is_deleted = FALSE)
-					AND id NOT IN (SELECT submission_id FROM submission_result WHERE status LIKE 'SYSTEM%')
-					AND status != 'GRADING'
-					AND user_id NOT IN (SELECT user_id FROM workspace_participant WHERE workspace_id = ? AND role IN ('ADMIN', 'OWNER'))
-			) as i1
-			WHERE i1.submitted_at < i1.due_date
+			SELECT s.*
+			FROM submission s
+			INNER JOIN assignment a ON a.id = s.assignment_id
+			LEFT JOIN assignment_extension e ON e.assignment_id = s.assignment_id AND e.user_id = s.user_id
+			WHERE
+				a.workspace_id = ? AND a.is_deleted = FALSE
+				AND s.id NOT IN (SELECT submission_id FROM submission_result WHERE status LIKE 'SYSTEM%')
+				AND s.status NOT IN ('GRADING', 'SYSTEM_FAILURE')
+				AND s.user_id NOT IN (SELECT user_id FROM workspace_participant WHERE workspace_id = ? AND role IN ('ADMIN', 'OWNER'))
+				AND (
+					COALESCE(e.due_date, a.due_date) IS NULL
+					OR a.late_policy != 'CUTOFF'
+					OR s.submitted_at < DATE_ADD(COALESCE(e.due_date, a.due_date), INTERVAL a.late_grace_period MINUTE)
+				)
 		)
 		SELECT
 			t1.user_id AS id, u.display_name, u.profile_url, t1.score, t2.total_submission, t3.last_submitted_at,
@@ -298,6 +296,15 @@ func (r *workspaceRepository) ListParticipant(
 	return participants, nil
 }
 
+func (r *workspaceRepository) ListLevel(workspaceId int) ([]domain.WorkspaceLevel, error) {
+	levels := make([]domain.WorkspaceLevel, 0)
+	err := r.db.Select(&levels, "SELECT * FROM workspace_level WHERE workspace_id = ? ORDER BY score ASC", workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query to list workspace level: %w", err)
+	}
+	return levels, nil
+}
+
 func (r *workspaceRepository) Update(userId string, workspace *domain.Workspace) error {
 	return r.db.ExecuteTx(func(tx *sqlx.Tx) error {
 		_, err := tx.NamedExec(`
@@ -347,6 +354,18 @@ func (r *workspaceRepository) UpdateParticipant(
 	return nil
 }
 
+func (r *workspaceRepository) UpdateLevel(level *domain.WorkspaceLevel) error {
+	_, err := r.db.NamedExec(`
+		INSERT INTO workspace_level (workspace_id, level, score)
+		VALUES (:workspace_id, :level, :score)
+		ON DUPLICATE KEY UPDATE score = VALUES(score)
+	`, level)
+	if err != nil {
+		return fmt.Errorf("cannot query to update workspace level: %w", err)
+	}
+	return nil
+}
+
 func (r *workspaceRepository) Delete(workspaceId int) error {
 	_, err := r.db.Exec(`
 		UPDATE workspace SET is_deleted = TRUE WHERE id = ?
@@ -374,3 +393,29 @@ func (r *workspaceRepository) DeleteParticipant(workspaceId int, userId string)
 	}
 	return nil
 }
+
+func (r *workspaceRepository) DeleteLevel(workspaceId int, level domain.AssignmentLevel) (bool, error) {
+	isDeleted := false
+	err := r.db.ExecuteTx(func(tx *sqlx.Tx) error {
+		var count int
+		err := tx.Get(&count, `
+			SELECT COUNT(*) FROM assignment
+			WHERE workspace_id = ? AND level = ? AND is_deleted = FALSE
+			FOR SHARE
+		`, workspaceId, level)
+		if err != nil {
+			return fmt.Errorf("cannot query to count assignment of workspace level: %w", err)
+		}
+		if count > 0 {
+			return nil
+		}
+
+		_, err = tx.Exec("DELETE FROM workspace_level WHERE workspace_id = ? AND level = ?", workspaceId, level)
+		if err != nil {
+			return fmt.Errorf("cannot query to delete workspace level: %w", err)
+		}
+		isDeleted = true
+		return nil
+	})
+	return isDeleted, err
+}
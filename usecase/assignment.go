@@ -1,36 +1,65 @@
 package usecase
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/codern-org/codern/domain"
 	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/archive"
+	"github.com/codern-org/codern/internal/config"
+	"github.com/codern-org/codern/internal/constant"
+	"github.com/codern-org/codern/internal/diff"
 	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/internal/ratelimit"
+	"github.com/codern-org/codern/internal/similarity"
 	"github.com/codern-org/codern/platform"
+	"go.uber.org/zap"
 )
 
 type assignmentUsecase struct {
+	logger               *zap.Logger
+	submissionLimiter    *ratelimit.TokenBucket
 	seaweedfs            *platform.SeaweedFs
 	assignmentRepository domain.AssignmentRepository
+	gradingRepository    domain.GradingRepository
 	gradingPublisher     domain.GradingPublisher
 	workspaceUsecase     domain.WorkspaceUsecase
+	languageUsecase      domain.LanguageUsecase
 }
 
 func NewAssignmentUsecase(
+	cfg *config.Config,
+	logger *zap.Logger,
 	seaweedfs *platform.SeaweedFs,
 	assignmentRepository domain.AssignmentRepository,
+	gradingRepository domain.GradingRepository,
 	gradingPublisher domain.GradingPublisher,
 	workspaceUsecase domain.WorkspaceUsecase,
+	languageUsecase domain.LanguageUsecase,
 ) domain.AssignmentUsecase {
 	return &assignmentUsecase{
+		logger: logger,
+		submissionLimiter: ratelimit.NewTokenBucket(
+			cfg.Grading.RateLimit.Capacity,
+			time.Duration(cfg.Grading.RateLimit.Interval)*time.Second,
+		),
 		seaweedfs:            seaweedfs,
 		assignmentRepository: assignmentRepository,
+		gradingRepository:    gradingRepository,
 		gradingPublisher:     gradingPublisher,
 		workspaceUsecase:     workspaceUsecase,
+		languageUsecase:      languageUsecase,
 	}
 }
 
@@ -47,6 +76,15 @@ func (u *assignmentUsecase) Create(
 		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
 	}
 
+	if err := u.validateLevel(workspaceId, ca.Level); err != nil {
+		return errs.New(errs.SameCode, "cannot validate level while creating assignment", err)
+	}
+	if ca.SolutionLanguage != nil {
+		if err := u.validateSolution(nil, workspaceId, *ca.SolutionLanguage, ca.SolutionFile); err != nil {
+			return errs.New(errs.SameCode, "cannot validate reference solution while creating assignment", err)
+		}
+	}
+
 	fileExt := "md"
 	if ca.DetailFile.MimeType == "application/pdf" {
 		fileExt = "pdf"
@@ -59,16 +97,50 @@ func (u *assignmentUsecase) Create(
 	)
 
 	assignment := &domain.Assignment{
-		Id:          id,
-		WorkspaceId: workspaceId,
-		Name:        ca.Name,
-		Description: ca.Description,
-		DetailUrl:   filePath,
-		MemoryLimit: ca.MemoryLimit,
-		TimeLimit:   ca.TimeLimit,
-		Level:       ca.Level,
-		PublishDate: ca.PublishDate,
-		DueDate:     ca.DueDate,
+		Id:                  id,
+		WorkspaceId:         workspaceId,
+		Name:                ca.Name,
+		Description:         ca.Description,
+		DetailUrl:           filePath,
+		MemoryLimit:         ca.MemoryLimit,
+		TimeLimit:           ca.TimeLimit,
+		Level:               ca.Level,
+		CustomMaxScore:      ca.MaxScore,
+		MaxAttempt:          ca.MaxAttempt,
+		GradingPriority:     domain.GradingPriorityNormal,
+		PublishDate:         ca.PublishDate,
+		DueDate:             ca.DueDate,
+		LatePolicy:          domain.LatePolicyCutoff,
+		LatePenaltyInterval: domain.LatePenaltyIntervalDay,
+		CheckerType:         domain.CheckerTypeExact,
+	}
+	if ca.GradingPriority != nil {
+		assignment.GradingPriority = *ca.GradingPriority
+	}
+	if ca.LatePolicy != nil {
+		assignment.LatePolicy = *ca.LatePolicy
+	}
+	if ca.LatePenalty != nil {
+		assignment.LatePenalty = *ca.LatePenalty
+	}
+	if ca.LatePenaltyInterval != nil {
+		assignment.LatePenaltyInterval = *ca.LatePenaltyInterval
+	}
+	if ca.LateGracePeriod != nil {
+		assignment.LateGracePeriod = *ca.LateGracePeriod
+	}
+	if ca.SubmissionInterval != nil {
+		assignment.SubmissionInterval = *ca.SubmissionInterval
+	}
+	// Zero attempt is unlimited as in updating an assignment
+	if ca.MaxAttempt != nil && *ca.MaxAttempt == 0 {
+		assignment.MaxAttempt = nil
+	}
+
+	if err := u.updateChecker(
+		assignment, ca.CheckerType, ca.CheckerEpsilon, ca.CheckerLanguage, ca.CheckerFile,
+	); err != nil {
+		return errs.New(errs.SameCode, "cannot update checker while creating assignment", err)
 	}
 
 	if err := u.assignmentRepository.Create(assignment); err != nil {
@@ -80,6 +152,21 @@ func (u *assignmentUsecase) Create(
 		return errs.New(errs.ErrFileSystem, "cannot upload file", err)
 	}
 
+	if ca.SolutionFile != nil {
+		if err := u.UpdateTemplate(
+			userId, id, *ca.SolutionLanguage, domain.TemplateTypeSolution, ca.SolutionFile.Reader,
+		); err != nil {
+			return errs.New(errs.SameCode, "cannot upload reference solution while creating assignment", err)
+		}
+	}
+
+	if ca.SolutionLanguage != nil {
+		if _, err := u.GenerateTestcases(id, ca.TestcaseFiles, *ca.SolutionLanguage); err != nil {
+			return errs.New(errs.SameCode, "cannot generate testcase while creating assignment", err)
+		}
+		return nil
+	}
+
 	if err := u.CreateTestcases(id, ca.TestcaseFiles); err != nil {
 		return errs.New(errs.SameCode, "cannot create testcase while creating assignment", err)
 	}
@@ -121,14 +208,63 @@ func (u *assignmentUsecase) Update(
 		assignment.TimeLimit = *ua.TimeLimit
 	}
 	if ua.Level != nil {
+		if err := u.validateLevel(assignment.WorkspaceId, *ua.Level); err != nil {
+			return errs.New(errs.SameCode, "cannot validate level while updating assignment", err)
+		}
 		assignment.Level = *ua.Level
 	}
+	if ua.MaxScore != nil {
+		// Zero max score resets the assignment back to the score of its level
+		assignment.CustomMaxScore = ua.MaxScore
+		if *ua.MaxScore == 0 {
+			assignment.CustomMaxScore = nil
+		}
+	}
+	if ua.GradingPriority != nil {
+		assignment.GradingPriority = *ua.GradingPriority
+	}
 	if ua.PublishDate != nil {
 		assignment.PublishDate = *ua.PublishDate
 	}
 
 	assignment.DueDate = ua.DueDate
 
+	if ua.LatePolicy != nil {
+		assignment.LatePolicy = *ua.LatePolicy
+	}
+	if ua.LatePenalty != nil {
+		assignment.LatePenalty = *ua.LatePenalty
+	}
+	if ua.LatePenaltyInterval != nil {
+		assignment.LatePenaltyInterval = *ua.LatePenaltyInterval
+	}
+	if ua.LateGracePeriod != nil {
+		assignment.LateGracePeriod = *ua.LateGracePeriod
+	}
+	if ua.MaxAttempt != nil {
+		// Zero max attempt removes the limit
+		assignment.MaxAttempt = ua.MaxAttempt
+		if *ua.MaxAttempt == 0 {
+			assignment.MaxAttempt = nil
+		}
+	}
+	if ua.SubmissionInterval != nil {
+		assignment.SubmissionInterval = *ua.SubmissionInterval
+	}
+	if ua.TestcaseFiles != nil && ua.SolutionLanguage != nil {
+		if err := u.validateSolution(
+			&assignmentId, assignment.WorkspaceId, *ua.SolutionLanguage, ua.SolutionFile,
+		); err != nil {
+			return errs.New(errs.SameCode, "cannot validate reference solution while updating assignment id %d", assignmentId, err)
+		}
+	}
+
+	if err := u.updateChecker(
+		assignment, ua.CheckerType, ua.CheckerEpsilon, ua.CheckerLanguage, ua.CheckerFile,
+	); err != nil {
+		return errs.New(errs.SameCode, "cannot update checker while updating assignment id %d", assignmentId, err)
+	}
+
 	fileExt := "md"
 	if ua.DetailFile.MimeType == "application/pdf" {
 		fileExt = "pdf"
@@ -147,7 +283,19 @@ func (u *assignmentUsecase) Update(
 		return errs.New(errs.ErrFileSystem, "cannot upload detail file while updating assignment id %d", assignmentId, err)
 	}
 
-	if ua.TestcaseFiles != nil {
+	if ua.SolutionFile != nil {
+		if err := u.UpdateTemplate(
+			userId, assignmentId, *ua.SolutionLanguage, domain.TemplateTypeSolution, ua.SolutionFile.Reader,
+		); err != nil {
+			return errs.New(errs.SameCode, "cannot upload reference solution while updating assignment id %d", assignmentId, err)
+		}
+	}
+
+	if ua.TestcaseFiles != nil && ua.SolutionLanguage != nil {
+		if _, err := u.GenerateTestcases(assignmentId, *ua.TestcaseFiles, *ua.SolutionLanguage); err != nil {
+			return errs.New(errs.SameCode, "cannot generate testcases by assignment id %d", assignmentId, err)
+		}
+	} else if ua.TestcaseFiles != nil {
 		if err := u.UpdateTestcases(assignmentId, *ua.TestcaseFiles); err != nil {
 			return errs.New(errs.ErrUpdateAssignment, "cannot update testcases by assignment id %d", assignmentId, err)
 		}
@@ -156,6 +304,105 @@ func (u *assignmentUsecase) Update(
 	return nil
 }
 
+// updateChecker applies the checker settings to an assignment and uploads
+// the program of a custom checker next to the testcases
+func (u *assignmentUsecase) updateChecker(
+	assignment *domain.Assignment,
+	checkerType *domain.CheckerType,
+	epsilon *float64,
+	language *string,
+	file *domain.File,
+) error {
+	if checkerType != nil {
+		assignment.CheckerType = *checkerType
+	}
+	if epsilon != nil {
+		assignment.CheckerEpsilon = epsilon
+	}
+	if language != nil {
+		assignment.CheckerLanguage = language
+	}
+
+	switch assignment.CheckerType {
+	case domain.CheckerTypeFloat:
+		if assignment.CheckerEpsilon == nil {
+			defaultEpsilon := constant.DefaultCheckerEpsilon
+			assignment.CheckerEpsilon = &defaultEpsilon
+		}
+	case domain.CheckerTypeCustom:
+		if assignment.CheckerLanguage == nil {
+			return errs.New(errs.ErrInvalidChecker, "custom checker requires a language")
+		}
+
+		lang, err := u.languageUsecase.Get(*assignment.CheckerLanguage, assignment.WorkspaceId)
+		if err != nil {
+			return errs.New(errs.SameCode, "cannot get checker language %s", *assignment.CheckerLanguage, err)
+		} else if lang == nil {
+			return errs.New(errs.ErrLanguageNotFound, "checker language %s not found", *assignment.CheckerLanguage)
+		}
+
+		if file == nil {
+			if assignment.CheckerFileUrl == nil {
+				return errs.New(errs.ErrInvalidChecker, "custom checker requires a checker file")
+			}
+			return nil
+		}
+
+		filePath := fmt.Sprintf(
+			"/workspaces/%d/assignments/%d/testcase/checker/checker.%s",
+			assignment.WorkspaceId, assignment.Id, lang.FileExtension,
+		)
+		// TODO: retry strategy, error
+		if err := u.seaweedfs.Upload(file.Reader, 0, filePath); err != nil {
+			return errs.New(errs.ErrFileSystem, "cannot upload checker file", err)
+		}
+		assignment.CheckerFileUrl = &filePath
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) validateLevel(workspaceId int, level domain.AssignmentLevel) error {
+	levels, err := u.workspaceUsecase.ListLevel(workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot list level of workspace id %d", workspaceId, err)
+	}
+	for i := range levels {
+		if levels[i].Level == level {
+			return nil
+		}
+	}
+	return errs.New(errs.ErrInvalidLevel, "level %s is not defined in workspace id %d", level, workspaceId)
+}
+
+// validateSolution checks that testcases can be generated by the reference solution of a language
+// before anything is saved, the solution is either uploaded along or already saved in the assignment
+func (u *assignmentUsecase) validateSolution(
+	assignmentId *int,
+	workspaceId int,
+	language string,
+	file *domain.File,
+) error {
+	lang, err := u.languageUsecase.Get(language, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get language %s", language, err)
+	} else if lang == nil {
+		return errs.New(errs.ErrLanguageNotFound, "language %s not found", language)
+	}
+
+	if file != nil {
+		return nil
+	}
+	if assignmentId != nil {
+		solution, err := u.assignmentRepository.GetTemplate(*assignmentId, lang.Id, domain.TemplateTypeSolution)
+		if err != nil {
+			return errs.New(errs.ErrListTemplate, "cannot get reference solution of assignment id %d", *assignmentId, err)
+		} else if solution != nil {
+			return nil
+		}
+	}
+	return errs.New(errs.ErrTemplateNotFound, "reference solution of language %s is required to generate testcases", lang.Id)
+}
+
 func (u *assignmentUsecase) Delete(userId string, id int) error {
 	assignment, err := u.Get(id)
 	if err != nil {
@@ -190,23 +437,38 @@ func (u *assignmentUsecase) CreateTestcases(assignmentId int, files []domain.Tes
 		return errs.New(errs.SameCode, "cannot get assignment id %d while creating testcase", assignmentId)
 	}
 
+	// Every testcase set is stored under a new revision to keep the previous ones readable
+	revision, err := u.assignmentRepository.GetTestcaseRevision(assignmentId)
+	if err != nil {
+		return errs.New(errs.ErrCreateTestcase, "cannot get testcase revision of assignment id %d", assignmentId, err)
+	}
+	revision += 1
+
 	testcases := make([]domain.Testcase, len(files))
 	for i, file := range files {
+		if file.Output == nil {
+			return errs.New(errs.ErrCreateTestcase, "cannot create testcase %d without output file", i+1)
+		}
+
 		id := generator.GetId()
 
 		inputFilePath := fmt.Sprintf(
-			"/workspaces/%d/assignments/%d/testcase/%d.in",
-			assignment.WorkspaceId, assignmentId, i+1,
+			"/workspaces/%d/assignments/%d/testcase/%d/%d.in",
+			assignment.WorkspaceId, assignmentId, revision, i+1,
 		)
 
 		outputFilePath := fmt.Sprintf(
-			"/workspaces/%d/assignments/%d/testcase/%d.out",
-			assignment.WorkspaceId, assignmentId, i+1,
+			"/workspaces/%d/assignments/%d/testcase/%d/%d.out",
+			assignment.WorkspaceId, assignmentId, revision, i+1,
 		)
 
 		testcases[i] = domain.Testcase{
 			Id:            id,
 			AssignmentId:  assignmentId,
+			Revision:      revision,
+			Group:         file.Group,
+			Weight:        file.Weight,
+			Visibility:    file.Visibility,
 			InputFileUrl:  inputFilePath,
 			OutputFileUrl: outputFilePath,
 		}
@@ -226,18 +488,188 @@ func (u *assignmentUsecase) CreateTestcases(assignmentId int, files []domain.Tes
 	return nil
 }
 
-func (u *assignmentUsecase) UpdateTestcases(assignmentId int, testcaseFiles []domain.TestcaseFile) error {
+// GenerateTestcases creates a testcase revision from inputs only, the outputs are filled in
+// from the reference solution by the grader and the revision is graded with once it is completed
+func (u *assignmentUsecase) GenerateTestcases(
+	assignmentId int,
+	files []domain.TestcaseFile,
+	language string,
+) (*domain.TestcaseGeneration, error) {
+	if len(files) == 0 {
+		return nil, errs.New(errs.ErrCreateTestcase, "cannot generate testcase, testcase files is empty")
+	}
+
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while generating testcase", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	lang, err := u.languageUsecase.Get(language, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get language %s while generating testcase", language, err)
+	} else if lang == nil {
+		return nil, errs.New(errs.ErrLanguageNotFound, "language %s not found", language)
+	}
+
+	solution, err := u.assignmentRepository.GetTemplate(assignmentId, lang.Id, domain.TemplateTypeSolution)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateTestcaseGeneration, "cannot get reference solution of assignment id %d", assignmentId, err)
+	} else if solution == nil {
+		return nil, errs.New(errs.ErrTemplateNotFound, "reference solution of language %s not found", lang.Id)
+	}
+
+	revision, err := u.assignmentRepository.GetTestcaseRevision(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateTestcase, "cannot get testcase revision of assignment id %d", assignmentId, err)
+	}
+	revision += 1
+
+	testcases := make([]domain.Testcase, len(files))
+	for i, file := range files {
+		inputFilePath := fmt.Sprintf(
+			"/workspaces/%d/assignments/%d/testcase/%d/%d.in",
+			assignment.WorkspaceId, assignmentId, revision, i+1,
+		)
+
+		outputFilePath := fmt.Sprintf(
+			"/workspaces/%d/assignments/%d/testcase/%d/%d.out",
+			assignment.WorkspaceId, assignmentId, revision, i+1,
+		)
+
+		testcases[i] = domain.Testcase{
+			Id:            generator.GetId(),
+			AssignmentId:  assignmentId,
+			Revision:      revision,
+			Group:         file.Group,
+			Weight:        file.Weight,
+			Visibility:    file.Visibility,
+			InputFileUrl:  inputFilePath,
+			OutputFileUrl: outputFilePath,
+		}
+
+		// TODO: retry strategy, error
+		if err := u.seaweedfs.Upload(file.Input, 0, inputFilePath); err != nil {
+			return nil, errs.New(errs.ErrFileSystem, "cannot upload testcase input file", err)
+		}
+	}
+
+	generation := &domain.TestcaseGeneration{
+		Id:           generator.GetId(),
+		AssignmentId: assignmentId,
+		Revision:     revision,
+		Language:     lang.Id,
+		Status:       domain.GenerationStatusGenerating,
+		Attempt:      1,
+	}
+	outbox, err := u.gradingPublisher.CreateGenerationOutbox(generation, assignment, testcases, solution, lang)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create generation request of assignment id %d", assignmentId, err)
+	}
+	if err := u.assignmentRepository.CreateTestcaseGeneration(generation, testcases, outbox); err != nil {
+		return nil, errs.New(errs.ErrCreateTestcaseGeneration, "cannot create testcase generation", err)
+	}
+
+	// The generation request is committed, the outbox relay retries it when it cannot be sent now
+	if _, err := u.gradingRepository.SendOutbox(
+		outbox.Id,
+		constant.GradingOutboxMaxBackoff,
+		u.gradingPublisher.PublishOutbox,
+	); err != nil {
+		u.logger.Error("Cannot send generation request right away", zap.Int("generation_id", generation.Id), zap.Error(err))
+	}
+
+	return generation, nil
+}
+
+// CreateTestcaseGenerationResults uploads the outputs of the reference solution, the generation
+// fails without uploading anything if the solution does not compile or fails on any input
+func (u *assignmentUsecase) CreateTestcaseGenerationResults(
+	id int,
+	compilationLog string,
+	outputs []domain.TestcaseOutput,
+) error {
+	generation, err := u.assignmentRepository.GetTestcaseGeneration(id)
+	if err != nil {
+		return errs.New(errs.ErrGetTestcaseGeneration, "cannot get testcase generation id %d", id, err)
+	} else if generation == nil {
+		return errs.New(errs.ErrTestcaseGenerationNotFound, "testcase generation id %d not found", id)
+	} else if generation.Status != domain.GenerationStatusGenerating {
+		return errs.New(errs.ErrDupTestcaseGeneration, "testcase generation id %d is already processed", id)
+	}
+
+	testcases, err := u.assignmentRepository.ListTestcase(generation.AssignmentId, generation.Revision)
+	if err != nil {
+		return errs.New(errs.ErrListTestcase, "cannot list testcase of generation id %d", id, err)
+	}
+
+	outputByTestcase := make(map[int]domain.TestcaseOutput, len(outputs))
+	for _, output := range outputs {
+		outputByTestcase[output.TestcaseId] = output
+	}
+
+	var failureLog *string
+	if len(compilationLog) > 0 {
+		failureLog = &compilationLog
+	}
+	for i := 0; failureLog == nil && i < len(testcases); i++ {
+		if output, ok := outputByTestcase[testcases[i].Id]; !ok || !output.IsPassed {
+			log := fmt.Sprintf("reference solution failed on testcase %d", i+1)
+			failureLog = &log
+		}
+	}
+
+	status := domain.GenerationStatusFailed
+	if failureLog == nil {
+		status = domain.GenerationStatusCompleted
+		for _, testcase := range testcases {
+			output := outputByTestcase[testcase.Id].Output
+			// TODO: retry strategy, error
+			if err := u.seaweedfs.Upload(strings.NewReader(output), 0, testcase.OutputFileUrl); err != nil {
+				return errs.New(errs.ErrFileSystem, "cannot upload generated testcase output file", err)
+			}
+		}
+	}
+
+	isUpdated, err := u.assignmentRepository.UpdateTestcaseGeneration(id, status, failureLog)
+	if err != nil {
+		return errs.New(errs.ErrUpdateTestcaseGeneration, "cannot update testcase generation id %d", id, err)
+	} else if !isUpdated {
+		return errs.New(errs.ErrDupTestcaseGeneration, "testcase generation id %d is already processed", id)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) GetLatestTestcaseGeneration(
+	userId string,
+	assignmentId int,
+) (*domain.TestcaseGeneration, error) {
 	assignment, err := u.Get(assignmentId)
 	if err != nil {
-		return errs.New(errs.SameCode, "cannot get assignment id %d while updating testcase", assignmentId, err)
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while getting testcase generation", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
 	}
 
-	testcaseFileUrl := fmt.Sprintf("/workspaces/%d/assignments/%d/testcase/", assignment.WorkspaceId, assignment.Id)
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while getting testcase generation", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
 
-	if err := u.seaweedfs.DeleteDirectory(testcaseFileUrl); err != nil {
-		return errs.New(errs.ErrFileSystem, "cannot delete testcase files while updating testcase by assignment id: %d", assignmentId, err)
+	generation, err := u.assignmentRepository.GetLatestTestcaseGeneration(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetTestcaseGeneration, "cannot get testcase generation of assignment id %d", assignmentId, err)
+	} else if generation == nil {
+		return nil, errs.New(errs.ErrTestcaseGenerationNotFound, "assignment id %d has no testcase generation", assignmentId)
 	}
+	return generation, nil
+}
 
+func (u *assignmentUsecase) UpdateTestcases(assignmentId int, testcaseFiles []domain.TestcaseFile) error {
 	if err := u.CreateTestcases(assignmentId, testcaseFiles); err != nil {
 		return errs.New(errs.SameCode, "cannot create new testcase by assignment id %d", assignmentId, err)
 	}
@@ -250,107 +682,1003 @@ func (u *assignmentUsecase) CreateSubmission(
 	assignmentId int,
 	workspaceId int,
 	language string,
-	file io.Reader,
-) error {
+	source *domain.SubmissionSource,
+) (bool, error) {
 	id := generator.GetId()
 	filePath := fmt.Sprintf(
 		"/workspaces/%d/assignments/%d/submissions/%s/%d",
 		workspaceId, assignmentId, userId, id,
 	)
 	submission := &domain.Submission{
-		Id:           id,
-		AssignmentId: assignmentId,
-		SubmitterId:  userId,
-		Language:     language,
-		FileUrl:      filePath,
+		Id:             id,
+		AssignmentId:   assignmentId,
+		SubmitterId:    userId,
+		Language:       language,
+		GradingAttempt: 1,
+		GradingRetry:   1,
+		FileUrl:        filePath,
 	}
 
-	assignment, err := u.GetWithStatus(assignmentId, userId)
+	// The grader needs the files of every testcase including the hidden ones
+	assignment, _, err := u.getPublishedWithStatus(assignmentId, userId)
 	if err != nil {
-		return errs.New(errs.SameCode, "cannot get assignment id %d", assignmentId, err)
+		return false, errs.New(errs.SameCode, "cannot get assignment id %d", assignmentId, err)
 	} else if assignment == nil {
-		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", id)
+		return false, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", id)
 	}
 
 	if len(assignment.Testcases) == 0 {
-		return errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
+		return false, errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
 	}
 
-	if err := u.assignmentRepository.CreateSubmission(submission, assignment.Testcases); err != nil {
-		return errs.New(errs.ErrCreateSubmission, "cannot create submission", err)
+	revision := assignment.Testcases[0].Revision
+	submission.TestcaseRevision = &revision
+
+	lang, err := u.languageUsecase.Get(language, workspaceId)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get language %s while creating submission", language, err)
+	} else if lang == nil {
+		return false, errs.New(errs.ErrLanguageNotFound, "language %s not found", language)
+	} else if !lang.IsEnabled {
+		return false, errs.New(errs.ErrLanguageDisabled, "language %s is disabled in workspace id %d", language, workspaceId)
 	}
 
-	// TODO: retry strategy, error
-	if err := u.seaweedfs.Upload(file, 0, filePath); err != nil {
-		return errs.New(errs.ErrFileSystem, "cannot upload file", err)
+	if err := validateSubmissionSource(source); err != nil {
+		return false, errs.New(errs.SameCode, "invalid source of submission", err)
+	}
+
+	checkQuota, err := u.checkSubmissionQuota(userId, workspaceId, assignment)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot create submission of assignment id %d", assignmentId, err)
+	}
+
+	if len(source.Files) == 0 {
+		// TODO: retry strategy, error
+		if err := u.seaweedfs.Upload(source.File, 0, filePath); err != nil {
+			return false, errs.New(errs.ErrFileSystem, "cannot upload file", err)
+		}
+	} else {
+		// A project is stored as a directory of its files
+		submission.EntryPoint = &source.EntryPoint
+		for _, file := range source.Files {
+			fileUrl := fmt.Sprintf("%s/%s", filePath, file.Path)
+			// TODO: retry strategy, error
+			if err := u.seaweedfs.Upload(file.Reader, file.Size, fileUrl); err != nil {
+				return false, errs.New(errs.ErrFileSystem, "cannot upload file %s", file.Path, err)
+			}
+			submission.Files = append(submission.Files, domain.SubmissionFile{
+				SubmissionId: id,
+				Path:         file.Path,
+				FileUrl:      fileUrl,
+				Size:         file.Size,
+			})
+		}
+	}
+
+	// The grading request is persisted along with the submission and published by the outbox relay
+	outbox, err := u.gradingPublisher.CreateOutbox(assignment, submission, lang)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot create grading request of submission id %d", id, err)
+	}
+
+	if err := u.assignmentRepository.CreateSubmission(submission, outbox, checkQuota); err != nil {
+		var domainErr *errs.DomainError
+		if errors.As(err, &domainErr) {
+			return false, errs.New(errs.SameCode, "cannot create submission of assignment id %d", assignmentId, err)
+		}
+		return false, errs.New(errs.ErrCreateSubmission, "cannot create submission", err)
+	}
+
+	// Publish right away to tell whether the submission is queued,
+	// the outbox relay retries it later if the broker does not confirm
+	isQueued, err := u.gradingRepository.SendOutbox(
+		outbox.Id,
+		constant.GradingOutboxMaxBackoff,
+		u.gradingPublisher.PublishOutbox,
+	)
+	if err != nil {
+		// The submission is already created and the relay still sends it, failing here
+		// would only make the user submit again and spend another attempt
+		u.logger.Error("Cannot send grading request right away", zap.Int("submission_id", id), zap.Error(err))
+		return false, nil
+	}
+	return isQueued, nil
+}
+
+// validateSubmissionSource enforces the file count and size policy of a project
+// and normalizes its file paths to stay inside the submission directory
+func validateSubmissionSource(source *domain.SubmissionSource) error {
+	if len(source.Files) == 0 {
+		if source.File == nil {
+			return errs.New(errs.ErrInvalidSubmissionFile, "submission has no source file")
+		}
+		return nil
+	}
+
+	if len(source.Files) > constant.MaxSubmissionFileCount {
+		return errs.New(errs.ErrSubmissionTooLarge, "submission exceeds %d files", constant.MaxSubmissionFileCount)
+	}
+
+	totalSize := 0
+	isEntryPointFound := false
+	pathSet := make(map[string]bool)
+	for i := range source.Files {
+		file := &source.Files[i]
+
+		filePath := path.Clean(strings.ReplaceAll(file.Path, "\\", "/"))
+		if filePath == "." || filePath == ".." || strings.HasPrefix(filePath, "/") || strings.HasPrefix(filePath, "../") {
+			return errs.New(errs.ErrInvalidSubmissionFile, "invalid file path %s", file.Path)
+		}
+		if pathSet[filePath] {
+			return errs.New(errs.ErrInvalidSubmissionFile, "duplicated file path %s, files in directories need their source paths or a zip archive", file.Path)
+		}
+		pathSet[filePath] = true
+		file.Path = filePath
+
+		totalSize += file.Size
+		if totalSize > constant.MaxSubmissionSize {
+			return errs.New(errs.ErrSubmissionTooLarge, "submission exceeds %d bytes", constant.MaxSubmissionSize)
+		}
+
+		if filePath == path.Clean(source.EntryPoint) {
+			isEntryPointFound = true
+		}
+	}
+
+	if !isEntryPointFound {
+		return errs.New(errs.ErrInvalidSubmissionFile, "entry point %s is not in the submission files", source.EntryPoint)
+	}
+	source.EntryPoint = path.Clean(source.EntryPoint)
+	return nil
+}
+
+// checkSubmissionQuota enforces the attempt and interval limits of an assignment on students,
+// the token bucket of the workspace applies to everyone to protect the graders. The limits are
+// checked again by the returned function while the submission is created, nil is returned for staff
+func (u *assignmentUsecase) checkSubmissionQuota(
+	userId string,
+	workspaceId int,
+	assignment *domain.AssignmentWithStatus,
+) (func(attemptCount int, lastSubmittedAt *time.Time) error, error) {
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while checking submission quota", err)
+	}
+
+	var checkQuota func(attemptCount int, lastSubmittedAt *time.Time) error
+	if !isAuthorized {
+		checkQuota = func(attemptCount int, lastSubmittedAt *time.Time) error {
+			if assignment.MaxAttempt != nil && attemptCount >= *assignment.MaxAttempt {
+				return errs.New(errs.ErrSubmissionNoAttempt, "user id %s used all %d attempts", userId, *assignment.MaxAttempt)
+			}
+
+			interval := time.Duration(assignment.SubmissionInterval) * time.Second
+			if lastSubmittedAt != nil && time.Since(*lastSubmittedAt) < interval {
+				return errs.New(errs.ErrSubmissionTooFrequent, "user id %s must wait %s between submissions", userId, interval)
+			}
+			return nil
+		}
+		// Checked before uploading the source to reject most of the submissions early
+		if err := checkQuota(assignment.AttemptCount, assignment.LastSubmittedAt); err != nil {
+			return nil, err
+		}
 	}
 
-	// TODO: inform submission on grading publisher error
-	return u.gradingPublisher.Grade(assignment, submission)
+	if !u.submissionLimiter.Allow(strconv.Itoa(workspaceId)) {
+		return nil, errs.New(errs.ErrSubmissionRateLimited, "workspace id %d exceeds the submission rate", workspaceId)
+	}
+	return checkQuota, nil
 }
 
 func (u *assignmentUsecase) CreateSubmissionResults(
 	assignment *domain.Assignment,
 	submissionId int,
+	attempt int,
 	compilationLog string,
 	results []domain.SubmissionResult,
 ) error {
-	status := domain.AssignmentStatusComplete
-	score := 0.0
+	submission, err := u.GetSubmission(submissionId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get submission id %d while creating submission result", submissionId, err)
+	} else if submission == nil {
+		return errs.New(errs.ErrGetSubmission, "submission id %d not found", submissionId)
+	}
+
+	// The late penalty is deducted from the due date extended for the submitter
+	extension, err := u.assignmentRepository.GetExtension(assignment.Id, submission.SubmitterId)
+	if err != nil {
+		return errs.New(errs.ErrGetAssignment, "cannot get extension of assignment id %d", assignment.Id, err)
+	}
+	extendedAssignment := *assignment
+	extendedAssignment.ApplyExtension(extension)
 
-	maxScore := assignment.GetMaxScore()
-	testcaseScore := maxScore / float64(len(assignment.Testcases))
+	status := domain.AssignmentStatusComplete
+	rawScore := 0.0
 
 	if len(compilationLog) == 0 {
 		for _, result := range results {
-			if result.IsPassed {
-				score += testcaseScore
-			} else {
+			if !result.IsPassed {
 				status = domain.AssignmentStatusIncompleted
 			}
 		}
-		score = math.Round(score*100) / 100
+		rawScore = assignment.GetScore(results)
 	} else {
 		status = domain.AssignmentStatusIncompleted
 	}
 
-	if err := u.assignmentRepository.CreateSubmissionResults(
+	isCreated, err := u.assignmentRepository.CreateSubmissionResults(
 		submissionId,
+		attempt,
 		compilationLog,
 		status,
-		score,
+		rawScore,
+		extendedAssignment.GetPenalizedScore(rawScore, submission.SubmittedAt),
 		results,
-	); err != nil {
+	)
+	if err != nil {
 		return errs.New(errs.ErrCreateSubmissionResult, "cannot update submission result", err)
+	} else if !isCreated {
+		return errs.New(errs.ErrDupSubmissionResult, "submission id %d attempt %d is already processed", submissionId, attempt)
 	}
 	return nil
 }
 
-func (u *assignmentUsecase) Get(id int) (*domain.Assignment, error) {
-	assignment, err := u.assignmentRepository.Get(id)
+func (u *assignmentUsecase) ReapTestcaseGenerations(
+	timeout time.Duration,
+	maxAttempt int,
+) ([]domain.TestcaseGeneration, error) {
+	generations, err := u.assignmentRepository.ListStuckTestcaseGeneration(timeout)
 	if err != nil {
-		return nil, errs.New(errs.ErrGetAssignment, "cannot get assignment id %d", id, err)
+		return nil, errs.New(errs.ErrGetTestcaseGeneration, "cannot list stuck testcase generation", err)
 	}
-	return assignment, nil
-}
 
-func (u *assignmentUsecase) GetWithStatus(id int, userId string) (*domain.AssignmentWithStatus, error) {
-	assignment, err := u.assignmentRepository.GetWithStatus(id, userId)
-	if err != nil {
-		return nil, errs.New(errs.ErrGetAssignment, "cannot get assignment id %d", id, err)
-	}
-	assignment.MaxScore = assignment.GetMaxScore()
+	failedGenerations := make([]domain.TestcaseGeneration, 0)
+	for i := range generations {
+		generation := &generations[i]
+
+		if generation.Attempt < maxAttempt {
+			outbox, err := u.createGenerationOutbox(generation)
+			if err != nil {
+				return failedGenerations, errs.New(errs.SameCode, "cannot create generation request while requeuing testcase generation id %d", generation.Id, err)
+			}
+			if outbox != nil {
+				generation.Attempt += 1
+				if _, err := u.assignmentRepository.RequeueTestcaseGeneration(generation, outbox); err != nil {
+					return failedGenerations, errs.New(errs.ErrUpdateTestcaseGeneration, "cannot requeue testcase generation id %d", generation.Id, err)
+				}
+				continue
+			}
+		}
+
+		log := "the grader did not run the reference solution in time"
+		isFailed, err := u.assignmentRepository.UpdateTestcaseGeneration(generation.Id, domain.GenerationStatusFailed, &log)
+		if err != nil {
+			return failedGenerations, errs.New(errs.ErrUpdateTestcaseGeneration, "cannot fail testcase generation id %d", generation.Id, err)
+		}
+		if isFailed {
+			generation.Status = domain.GenerationStatusFailed
+			failedGenerations = append(failedGenerations, *generation)
+		}
+	}
+
+	return failedGenerations, nil
+}
+
+// createGenerationOutbox rebuilds the request of a testcase generation, nil is returned
+// when the assignment, the testcases, the language or the reference solution is gone
+func (u *assignmentUsecase) createGenerationOutbox(generation *domain.TestcaseGeneration) (*domain.GradingOutbox, error) {
+	assignment, err := u.Get(generation.AssignmentId)
+	if err != nil || assignment == nil {
+		return nil, err
+	}
+
+	testcases, err := u.assignmentRepository.ListTestcase(generation.AssignmentId, generation.Revision)
+	if err != nil {
+		return nil, errs.New(errs.ErrListTestcase, "cannot list testcase of assignment id %d", generation.AssignmentId, err)
+	} else if len(testcases) == 0 {
+		return nil, nil
+	}
+
+	language, err := u.languageUsecase.Get(generation.Language, assignment.WorkspaceId)
+	if err != nil || language == nil {
+		return nil, err
+	}
+
+	solution, err := u.assignmentRepository.GetTemplate(generation.AssignmentId, language.Id, domain.TemplateTypeSolution)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateTestcaseGeneration, "cannot get reference solution of assignment id %d", generation.AssignmentId, err)
+	} else if solution == nil {
+		return nil, nil
+	}
+
+	return u.gradingPublisher.CreateGenerationOutbox(generation, assignment, testcases, solution, language)
+}
+
+func (u *assignmentUsecase) ReapSubmissions(timeout time.Duration, maxAttempt int) ([]domain.Submission, error) {
+	submissions, err := u.assignmentRepository.ListStuckSubmission(timeout)
+	if err != nil {
+		return nil, errs.New(errs.ErrListSubmission, "cannot list stuck submission", err)
+	}
+
+	failedSubmissions := make([]domain.Submission, 0)
+	for i := range submissions {
+		submission := &submissions[i]
+
+		assignment, err := u.assignmentRepository.GetWithStatus(submission.AssignmentId, submission.SubmitterId)
+		if err != nil {
+			return failedSubmissions, errs.New(errs.ErrGetAssignment, "cannot get assignment id %d while reaping submission", submission.AssignmentId, err)
+		}
+
+		if submission.GradingRetry < maxAttempt && assignment != nil && len(assignment.Testcases) > 0 {
+			submission.GradingAttempt += 1
+			submission.GradingRetry += 1
+			revision := assignment.Testcases[0].Revision
+			submission.TestcaseRevision = &revision
+
+			// The language might be removed after submitting, fallback to the default routing key
+			language, err := u.languageUsecase.Get(submission.Language, assignment.WorkspaceId)
+			if err != nil {
+				return failedSubmissions, errs.New(errs.SameCode, "cannot get language while requeuing submission id %d", submission.Id, err)
+			}
+
+			outbox, err := u.gradingPublisher.CreateOutbox(assignment, submission, language)
+			if err != nil {
+				return failedSubmissions, errs.New(errs.SameCode, "cannot create grading request while requeuing submission id %d", submission.Id, err)
+			}
+			// A requeued regrade keeps its low priority instead of the priority of the assignment
+			lastOutbox, err := u.gradingRepository.GetLatestOutbox(submission.Id)
+			if err != nil {
+				return failedSubmissions, errs.New(errs.ErrRequeueSubmission, "cannot get grading request of submission id %d", submission.Id, err)
+			} else if lastOutbox != nil {
+				outbox.Priority = lastOutbox.Priority
+			}
+			if err := u.assignmentRepository.RequeueSubmission(submission, outbox); err != nil {
+				return failedSubmissions, errs.New(errs.ErrRequeueSubmission, "cannot requeue submission id %d", submission.Id, err)
+			}
+			continue
+		}
+
+		isFailed, err := u.assignmentRepository.FailSubmission(submission.Id)
+		if err != nil {
+			return failedSubmissions, errs.New(errs.ErrUpdateSubmission, "cannot fail submission id %d", submission.Id, err)
+		}
+		if isFailed {
+			submission.Status = domain.AssignmentStatusSystemFailure
+			failedSubmissions = append(failedSubmissions, *submission)
+		}
+	}
+
+	return failedSubmissions, nil
+}
+
+func (u *assignmentUsecase) Regrade(
+	userId string,
+	assignmentId int,
+	filter *domain.RegradeFilter,
+) (*domain.RegradeJob, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while regrading", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while regrading", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	if len(assignment.Testcases) == 0 {
+		return nil, errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
+	}
+
+	submissions, err := u.assignmentRepository.ListRegradeSubmission(assignmentId, filter)
+	if err != nil {
+		return nil, errs.New(errs.ErrListSubmission, "cannot list submission to regrade assignment id %d", assignmentId, err)
+	}
+
+	extensions, err := u.assignmentRepository.ListExtension(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListExtension, "cannot list extension to regrade assignment id %d", assignmentId, err)
+	}
+	extensionByUserId := make(map[string]*domain.AssignmentExtension)
+	for i := range extensions {
+		extensionByUserId[extensions[i].UserId] = &extensions[i]
+	}
+
+	revision := assignment.Testcases[0].Revision
+	languageById := make(map[string]*domain.Language)
+	outboxes := make([]domain.GradingOutbox, 0, len(submissions))
+
+	for i := range submissions {
+		submission := &submissions[i]
+		// A regrade has its own retries while the attempt keeps telling the newest result
+		submission.GradingAttempt += 1
+		submission.GradingRetry = 1
+		submission.TestcaseRevision = &revision
+
+		language, ok := languageById[submission.Language]
+		if !ok {
+			language, err = u.languageUsecase.Get(submission.Language, assignment.WorkspaceId)
+			if err != nil {
+				return nil, errs.New(errs.SameCode, "cannot get language while regrading submission id %d", submission.Id, err)
+			}
+			languageById[submission.Language] = language
+		}
+
+		assignmentWithStatus := &domain.AssignmentWithStatus{Assignment: *assignment}
+		assignmentWithStatus.ApplyExtension(extensionByUserId[submission.SubmitterId])
+
+		outbox, err := u.gradingPublisher.CreateOutbox(assignmentWithStatus, submission, language)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot create grading request while regrading submission id %d", submission.Id, err)
+		}
+		// Regrading must not delay the submissions of students
+		outbox.Priority = domain.GradingPriorityMap[domain.GradingPriorityLow]
+		outboxes = append(outboxes, *outbox)
+	}
+
+	job := &domain.RegradeJob{
+		Id:               generator.GetId(),
+		AssignmentId:     assignmentId,
+		RequesterId:      userId,
+		TestcaseRevision: revision,
+		Total:            len(submissions),
+		CreatedAt:        time.Now(),
+	}
+	if err := u.assignmentRepository.CreateRegradeJob(job, submissions, outboxes); err != nil {
+		return nil, errs.New(errs.ErrCreateRegradeJob, "cannot create regrade job of assignment id %d", assignmentId, err)
+	}
+
+	return job, nil
+}
+
+func (u *assignmentUsecase) GetRegradeJob(userId string, assignmentId int, id int) (*domain.RegradeJob, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while getting regrade job", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while getting regrade job", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	job, err := u.assignmentRepository.GetRegradeJob(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetRegradeJob, "cannot get regrade job id %d", id, err)
+	} else if job == nil || job.AssignmentId != assignmentId {
+		return nil, errs.New(errs.ErrRegradeJobNotFound, "regrade job id %d not found", id)
+	}
+	return job, nil
+}
+
+func (u *assignmentUsecase) DetectSimilarity(userId string, assignmentId int) (*domain.SimilarityJob, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while detecting similarity", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while detecting similarity", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	latestJob, err := u.assignmentRepository.GetLatestSimilarityJob(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetSimilarityJob, "cannot get similarity job of assignment id %d", assignmentId, err)
+	}
+	// A job interrupted by a restart is never finished
+	if latestJob != nil && latestJob.Status == domain.SimilarityStatusRunning &&
+		time.Since(latestJob.CreatedAt) >= constant.SimilarityJobTimeout {
+		log := "similarity job timed out"
+		if _, err := u.assignmentRepository.UpdateSimilarityJob(latestJob.Id, domain.SimilarityStatusFailed, &log, nil); err != nil {
+			return nil, errs.New(errs.ErrUpdateSimilarityJob, "cannot fail similarity job id %d", latestJob.Id, err)
+		}
+	}
+
+	submissions, err := u.assignmentRepository.ListRegradeSubmission(
+		assignmentId,
+		&domain.RegradeFilter{IsLatestOnly: true},
+	)
+	if err != nil {
+		return nil, errs.New(errs.ErrListSubmission, "cannot list submission to detect similarity of assignment id %d", assignmentId, err)
+	}
+	// Submissions of a user at the same time are all latest
+	latestSubmissions := make([]domain.Submission, 0, len(submissions))
+	isSubmitted := make(map[string]bool)
+	for _, submission := range submissions {
+		if !isSubmitted[submission.SubmitterId] {
+			isSubmitted[submission.SubmitterId] = true
+			latestSubmissions = append(latestSubmissions, submission)
+		}
+	}
+
+	job := &domain.SimilarityJob{
+		Id:           generator.GetId(),
+		AssignmentId: assignmentId,
+		RequesterId:  userId,
+		Status:       domain.SimilarityStatusRunning,
+		Total:        len(latestSubmissions),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	isCreated, err := u.assignmentRepository.CreateSimilarityJob(job, constant.SimilarityJobTimeout)
+	if err != nil {
+		return nil, errs.New(errs.ErrCreateSimilarityJob, "cannot create similarity job of assignment id %d", assignmentId, err)
+	} else if !isCreated {
+		return nil, errs.New(errs.ErrDupSimilarityJob, "similarity job of assignment id %d is running", assignmentId)
+	}
+
+	go u.runSimilarityJob(job.Id, latestSubmissions)
+
+	return job, nil
+}
+
+// runSimilarityJob finishes a similarity job with its pairs or the log of its failure
+func (u *assignmentUsecase) runSimilarityJob(id int, submissions []domain.Submission) {
+	status := domain.SimilarityStatusCompleted
+	var log *string
+
+	// A panic of a background job would crash the server, the job is failed instead
+	defer func() {
+		if r := recover(); r != nil {
+			u.logger.Error("Similarity job panicked", zap.Int("job_id", id), zap.Any("panic", r))
+			message := fmt.Sprintf("similarity job panicked: %v", r)
+			if _, err := u.assignmentRepository.UpdateSimilarityJob(id, domain.SimilarityStatusFailed, &message, nil); err != nil {
+				u.logger.Error("Cannot fail similarity job", zap.Int("job_id", id), zap.Error(err))
+			}
+		}
+	}()
+
+	pairs, err := u.detectSimilarity(id, submissions)
+	if err != nil {
+		u.logger.Warn("Cannot detect similarity", zap.Int("job_id", id), zap.Error(err))
+		status = domain.SimilarityStatusFailed
+		message := err.Error()
+		log = &message
+	}
+
+	// The job is left running to be timed out when the result cannot be saved
+	if _, err := u.assignmentRepository.UpdateSimilarityJob(id, status, log, pairs); err != nil {
+		u.logger.Error("Cannot save result of similarity job", zap.Int("job_id", id), zap.Error(err))
+	}
+}
+
+func (u *assignmentUsecase) detectSimilarity(jobId int, submissions []domain.Submission) ([]domain.SimilarityPair, error) {
+	fingerprints := make([]similarity.Fingerprint, len(submissions))
+	for i := range submissions {
+		contents, err := u.readSubmissionSource(&submissions[i])
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot read source of submission id %d", submissions[i].Id, err)
+		}
+
+		tokens := make([]string, 0)
+		for _, content := range contents {
+			tokens = append(tokens, similarity.Tokenize([]byte(content.Content))...)
+		}
+		fingerprints[i] = similarity.Winnow(tokens, constant.SimilarityKGramSize, constant.SimilarityWindowSize)
+	}
+
+	pairs := make([]domain.SimilarityPair, 0)
+	for i := range submissions {
+		for j := i + 1; j < len(submissions); j++ {
+			score := fingerprints[i].Similarity(fingerprints[j])
+			if score < constant.MinSimilarityScore {
+				continue
+			}
+			pairs = append(pairs, domain.SimilarityPair{
+				JobId:             jobId,
+				SubmissionId:      submissions[i].Id,
+				SubmitterId:       submissions[i].SubmitterId,
+				OtherSubmissionId: submissions[j].Id,
+				OtherSubmitterId:  submissions[j].SubmitterId,
+				Score:             math.Round(score*10000) / 10000,
+			})
+		}
+	}
+	return pairs, nil
+}
+
+// readSubmissionSource downloads a single file submission or every file of a multi-file submission
+func (u *assignmentUsecase) readSubmissionSource(submission *domain.Submission) ([]domain.SourceContent, error) {
+	if len(submission.Files) == 0 {
+		content, err := u.downloadSource(submission.FileUrl)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.SourceContent{{Content: content}}, nil
+	}
+
+	contents := make([]domain.SourceContent, 0, len(submission.Files))
+	for _, file := range submission.Files {
+		content, err := u.downloadSource(file.FileUrl)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, domain.SourceContent{Path: file.Path, Content: content})
+	}
+	return contents, nil
+}
+
+func (u *assignmentUsecase) downloadSource(fileUrl string) (string, error) {
+	var content []byte
+	if err := u.seaweedfs.Download(fileUrl, func(r io.Reader) error {
+		var err error
+		content, err = io.ReadAll(io.LimitReader(r, int64(constant.MaxSubmissionSize)))
+		return err
+	}); err != nil {
+		return "", errs.New(errs.ErrFileSystem, "cannot download %s", fileUrl, err)
+	}
+	return string(content), nil
+}
+
+func (u *assignmentUsecase) GetSimilarityReport(userId string, assignmentId int, id int) (*domain.SimilarityReport, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while getting similarity report", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while getting similarity report", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	job, err := u.assignmentRepository.GetSimilarityJob(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetSimilarityJob, "cannot get similarity job id %d", id, err)
+	} else if job == nil || job.AssignmentId != assignmentId {
+		return nil, errs.New(errs.ErrSimilarityJobNotFound, "similarity job id %d not found", id)
+	}
+
+	pairs, err := u.assignmentRepository.ListSimilarityPair(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrListSimilarityPair, "cannot list similarity pair of job id %d", id, err)
+	}
+	return &domain.SimilarityReport{SimilarityJob: *job, Pairs: pairs}, nil
+}
+
+func (u *assignmentUsecase) CompareSimilarity(
+	userId string,
+	assignmentId int,
+	id int,
+	submissionId int,
+	otherSubmissionId int,
+) (*domain.SimilarityComparison, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while comparing similarity", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while comparing similarity", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	job, err := u.assignmentRepository.GetSimilarityJob(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetSimilarityJob, "cannot get similarity job id %d", id, err)
+	} else if job == nil || job.AssignmentId != assignmentId {
+		return nil, errs.New(errs.ErrSimilarityJobNotFound, "similarity job id %d not found", id)
+	}
+
+	pair, err := u.assignmentRepository.GetSimilarityPair(id, submissionId, otherSubmissionId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetSimilarityPair, "cannot get similarity pair of job id %d", id, err)
+	} else if pair == nil {
+		return nil, errs.New(
+			errs.ErrSimilarityPairNotFound,
+			"similarity pair of submission id %d and %d not found in job id %d",
+			submissionId, otherSubmissionId, id,
+		)
+	}
+
+	comparison := &domain.SimilarityComparison{SimilarityPair: *pair}
+	sources := []*[]domain.SourceContent{&comparison.Source, &comparison.OtherSource}
+	for i, submissionId := range []int{pair.SubmissionId, pair.OtherSubmissionId} {
+		submission, err := u.assignmentRepository.GetSubmission(submissionId)
+		if err != nil {
+			return nil, errs.New(errs.ErrGetSubmission, "cannot get submission id %d", submissionId, err)
+		} else if submission == nil {
+			return nil, errs.New(errs.ErrGetSubmission, "submission id %d not found", submissionId)
+		}
+
+		*sources[i], err = u.readSubmissionSource(submission)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot read source of submission id %d", submissionId, err)
+		}
+	}
+	return comparison, nil
+}
+
+func (u *assignmentUsecase) UpdateExtension(
+	userId string,
+	assignmentId int,
+	extension *domain.AssignmentExtension,
+) error {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get assignment id %d while updating extension", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while updating extension", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	role, err := u.workspaceUsecase.GetRole(extension.UserId, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get role of user id %s while updating extension", extension.UserId, err)
+	} else if role == nil {
+		return errs.New(errs.ErrExtensionNoUser, "user id %s is not in workspace id %d", extension.UserId, assignment.WorkspaceId)
+	}
+
+	extension.AssignmentId = assignmentId
+	if err := u.assignmentRepository.UpdateExtension(extension); err != nil {
+		return errs.New(errs.ErrUpdateExtension, "cannot update extension of assignment id %d", assignmentId, err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) DeleteExtension(userId string, assignmentId int, participantId string) error {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get assignment id %d while deleting extension", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while deleting extension", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	extension, err := u.assignmentRepository.GetExtension(assignmentId, participantId)
+	if err != nil {
+		return errs.New(errs.ErrDeleteExtension, "cannot get extension of user id %s", participantId, err)
+	} else if extension == nil {
+		return errs.New(errs.ErrExtensionNotFound, "extension of user id %s not found", participantId)
+	}
+
+	if err := u.assignmentRepository.DeleteExtension(assignmentId, participantId); err != nil {
+		return errs.New(errs.ErrDeleteExtension, "cannot delete extension of user id %s", participantId, err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) ListExtension(userId string, assignmentId int) ([]domain.AssignmentExtension, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while listing extension", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while listing extension", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	extensions, err := u.assignmentRepository.ListExtension(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListExtension, "cannot list extension of assignment id %d", assignmentId, err)
+	}
+	return extensions, nil
+}
+
+func (u *assignmentUsecase) UpdateTemplate(
+	userId string,
+	assignmentId int,
+	language string,
+	templateType domain.TemplateType,
+	file io.Reader,
+) error {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get assignment id %d while updating template", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while updating template", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	lang, err := u.languageUsecase.Get(language, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get language %s while updating template", language, err)
+	} else if lang == nil {
+		return errs.New(errs.ErrLanguageNotFound, "language %s not found", language)
+	}
+
+	filePath := fmt.Sprintf(
+		"/workspaces/%d/assignments/%d/template/%s/%s.%s",
+		assignment.WorkspaceId, assignmentId, strings.ToLower(string(templateType)), lang.Id, lang.FileExtension,
+	)
+	// TODO: retry strategy, error
+	if err := u.seaweedfs.Upload(file, 0, filePath); err != nil {
+		return errs.New(errs.ErrFileSystem, "cannot upload template file", err)
+	}
+
+	template := &domain.AssignmentTemplate{
+		AssignmentId: assignmentId,
+		Language:     lang.Id,
+		Type:         templateType,
+		FileUrl:      filePath,
+	}
+	if err := u.assignmentRepository.UpdateTemplate(template); err != nil {
+		return errs.New(errs.ErrUpdateTemplate, "cannot update template of assignment id %d", assignmentId, err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) DeleteTemplate(
+	userId string,
+	assignmentId int,
+	language string,
+	templateType domain.TemplateType,
+) error {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get assignment id %d while deleting template", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while deleting template", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	template, err := u.assignmentRepository.GetTemplate(assignmentId, language, templateType)
+	if err != nil {
+		return errs.New(errs.ErrDeleteTemplate, "cannot get template of assignment id %d", assignmentId, err)
+	} else if template == nil {
+		return errs.New(errs.ErrTemplateNotFound, "%s template of language %s not found", templateType, language)
+	}
+
+	if err := u.assignmentRepository.DeleteTemplate(assignmentId, language, templateType); err != nil {
+		return errs.New(errs.ErrDeleteTemplate, "cannot delete template of assignment id %d", assignmentId, err)
+	}
+	// TODO: retry strategy, error
+	if err := u.seaweedfs.Delete(template.FileUrl, nil); err != nil {
+		return errs.New(errs.ErrFileSystem, "cannot delete template file", err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) ListTemplate(userId string, assignmentId int) ([]domain.AssignmentTemplate, error) {
+	// Getting with status hides an unpublished assignment from a member
+	assignment, err := u.GetWithStatus(assignmentId, userId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while listing template", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while listing template", err)
+	}
+
+	templates, err := u.assignmentRepository.ListTemplate(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListTemplate, "cannot list template of assignment id %d", assignmentId, err)
+	}
+	if isAuthorized {
+		return templates, nil
+	}
+
+	starterTemplates := make([]domain.AssignmentTemplate, 0, len(templates))
+	for i := range templates {
+		if templates[i].Type == domain.TemplateTypeStarter {
+			starterTemplates = append(starterTemplates, templates[i])
+		}
+	}
+	return starterTemplates, nil
+}
+
+func (u *assignmentUsecase) Get(id int) (*domain.Assignment, error) {
+	assignment, err := u.assignmentRepository.Get(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetAssignment, "cannot get assignment id %d", id, err)
+	}
+	return assignment, nil
+}
+
+func (u *assignmentUsecase) GetByRevision(id int, revision int) (*domain.Assignment, error) {
+	assignment, err := u.Get(id)
+	if err != nil || assignment == nil {
+		return assignment, err
+	}
+
+	if len(assignment.Testcases) > 0 && assignment.Testcases[0].Revision == revision {
+		return assignment, nil
+	}
+
+	testcases, err := u.assignmentRepository.ListTestcase(id, revision)
+	if err != nil {
+		return nil, errs.New(errs.ErrListTestcase, "cannot list testcase revision %d of assignment id %d", revision, id, err)
+	}
+	assignment.Testcases = testcases
+	return assignment, nil
+}
+
+func (u *assignmentUsecase) GetWithStatus(id int, userId string) (*domain.AssignmentWithStatus, error) {
+	assignment, isAuthorized, err := u.getPublishedWithStatus(id, userId)
+	if err != nil || assignment == nil {
+		return nil, err
+	}
+	if !isAuthorized {
+		assignment.HideTestcases(nil, time.Now())
+	}
+	return assignment, nil
+}
+
+// getPublishedWithStatus gets an assignment with the testcases of every visibility,
+// an unpublished assignment is hidden from a member
+func (u *assignmentUsecase) getPublishedWithStatus(
+	id int,
+	userId string,
+) (*domain.AssignmentWithStatus, bool, error) {
+	assignment, err := u.assignmentRepository.GetWithStatus(id, userId)
+	if err != nil {
+		return nil, false, errs.New(errs.ErrGetAssignment, "cannot get assignment id %d", id, err)
+	} else if assignment == nil {
+		return nil, false, nil
+	}
+	assignment.MaxScore = assignment.GetMaxScore()
 
 	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
 	if err != nil {
-		return nil, errs.New(errs.SameCode, "cannot get workspace role while get assignment with status", err)
+		return nil, false, errs.New(errs.SameCode, "cannot get workspace role while get assignment with status", err)
 	}
 
 	if !isAuthorized && time.Now().Before(assignment.PublishDate) {
-		return nil, errs.New(errs.ErrGetAssignment, "invalid assignment id %d", id, err)
+		return nil, false, errs.New(errs.ErrGetAssignment, "invalid assignment id %d", id)
 	}
-
-	return assignment, nil
+	return assignment, isAuthorized, nil
 }
 
 func (u *assignmentUsecase) GetSubmission(id int) (*domain.Submission, error) {
@@ -361,6 +1689,86 @@ func (u *assignmentUsecase) GetSubmission(id int) (*domain.Submission, error) {
 	return submission, nil
 }
 
+func (u *assignmentUsecase) DiffSubmission(
+	userId string,
+	assignmentId int,
+	submissionId int,
+	otherSubmissionId int,
+) (*domain.SubmissionDiff, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while diffing submission", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	submissions := make([]*domain.Submission, 2)
+	for i, id := range []int{submissionId, otherSubmissionId} {
+		submission, err := u.GetSubmission(id)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot get submission id %d while diffing submission", id, err)
+		} else if submission == nil || submission.AssignmentId != assignmentId {
+			return nil, errs.New(errs.ErrSubmissionNotFound, "submission id %d not found in assignment id %d", id, assignmentId)
+		}
+		submissions[i] = submission
+	}
+	submitterId := submissions[0].SubmitterId
+	if submissions[1].SubmitterId != submitterId {
+		return nil, errs.New(errs.ErrInvalidSubmissionDiff, "submission id %d and %d are not of the same user", submissionId, otherSubmissionId)
+	}
+
+	userRole, err := u.workspaceUsecase.GetRole(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while diffing submission", err)
+	} else if userRole == nil || (*userRole == domain.MemberRole && userId != submitterId) {
+		return nil, errs.New(errs.ErrFilePerm, "no permission to diff submission not own")
+	}
+
+	sources := make([]map[string]string, 2)
+	for i, submission := range submissions {
+		contents, err := u.readSubmissionSource(submission)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot read source of submission id %d", submission.Id, err)
+		}
+		sources[i] = make(map[string]string)
+		for _, content := range contents {
+			sources[i][content.Path] = content.Content
+		}
+	}
+
+	paths := make([]string, 0, len(sources[0]))
+	for filePath := range sources[0] {
+		paths = append(paths, filePath)
+	}
+	for filePath := range sources[1] {
+		if _, ok := sources[0][filePath]; !ok {
+			paths = append(paths, filePath)
+		}
+	}
+	sort.Strings(paths)
+
+	var result strings.Builder
+	for _, filePath := range paths {
+		// A file added or removed is diffed against nothing like git does
+		oldName, newName := "/dev/null", "/dev/null"
+		oldText, isOldFound := sources[0][filePath]
+		newText, isNewFound := sources[1][filePath]
+		if isOldFound {
+			oldName = path.Join(strconv.Itoa(submissionId), filePath)
+		}
+		if isNewFound {
+			newName = path.Join(strconv.Itoa(otherSubmissionId), filePath)
+		}
+		result.WriteString(diff.Unified(oldName, newName, oldText, newText, constant.SubmissionDiffContext))
+	}
+
+	return &domain.SubmissionDiff{
+		SubmissionId:      submissionId,
+		OtherSubmissionId: otherSubmissionId,
+		Diff:              result.String(),
+	}, nil
+}
+
 func (u *assignmentUsecase) List(userId string, workspaceId int) ([]domain.AssignmentWithStatus, error) {
 	assignments, err := u.assignmentRepository.List(userId, workspaceId)
 	if err != nil {
@@ -382,6 +1790,7 @@ func (u *assignmentUsecase) List(userId string, workspaceId int) ([]domain.Assig
 	filteredAssignments := make([]domain.AssignmentWithStatus, 0, len(assignments))
 	for _, assignment := range assignments {
 		if time.Now().After(assignment.PublishDate) {
+			assignment.HideTestcases(nil, time.Now())
 			filteredAssignments = append(filteredAssignments, assignment)
 		}
 	}
@@ -393,6 +1802,9 @@ func (u *assignmentUsecase) ListSubmission(userId string, assignmentId int) ([]d
 	if err != nil {
 		return nil, errs.New(errs.ErrListSubmission, "cannot list submission", err)
 	}
+	if err := u.HideSubmissionResults(userId, assignmentId, submissions); err != nil {
+		return nil, errs.New(errs.SameCode, "cannot hide submission results of assignment id %d", assignmentId, err)
+	}
 	return submissions, nil
 }
 
@@ -415,3 +1827,464 @@ func (u *assignmentUsecase) ListAllSubmission(
 	}
 	return submissions, nil
 }
+
+func (u *assignmentUsecase) HideSubmissionResults(
+	userId string,
+	assignmentId int,
+	submissions []domain.Submission,
+) error {
+	if len(submissions) == 0 {
+		return nil
+	}
+
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get assignment id %d while hiding submission results", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while hiding submission results", err)
+	}
+	if isAuthorized {
+		return nil
+	}
+
+	extension, err := u.assignmentRepository.GetExtension(assignmentId, userId)
+	if err != nil {
+		return errs.New(errs.ErrGetAssignment, "cannot get extension of assignment id %d", assignmentId, err)
+	}
+	assignment.ApplyExtension(extension)
+
+	// Submissions are graded with different revisions, testcase ids are unique across them
+	latestRevision := 0
+	if len(assignment.Testcases) > 0 {
+		latestRevision = assignment.Testcases[0].Revision
+	}
+	isLoaded := map[int]bool{latestRevision: true}
+	for _, submission := range submissions {
+		if submission.TestcaseRevision == nil || isLoaded[*submission.TestcaseRevision] {
+			continue
+		}
+		revision := *submission.TestcaseRevision
+		testcases, err := u.assignmentRepository.ListTestcase(assignmentId, revision)
+		if err != nil {
+			return errs.New(errs.ErrListTestcase, "cannot list testcase revision %d of assignment id %d", revision, assignmentId, err)
+		}
+		assignment.Testcases = append(assignment.Testcases, testcases...)
+		isLoaded[revision] = true
+	}
+
+	assignment.HideTestcases(submissions, time.Now())
+	return nil
+}
+
+func (u *assignmentUsecase) IsTestcaseFileVisible(userId string, assignmentId int, fileUrl string) (bool, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get assignment id %d while getting testcase file", assignmentId, err)
+	} else if assignment == nil {
+		return false, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return false, errs.New(errs.SameCode, "cannot get workspace role while getting testcase file", err)
+	}
+	if isAuthorized {
+		return true, nil
+	}
+
+	testcase, err := u.assignmentRepository.GetTestcaseByFileUrl(assignmentId, fileUrl)
+	if err != nil {
+		return false, errs.New(errs.ErrListTestcase, "cannot get testcase of assignment id %d", assignmentId, err)
+	}
+	return testcase != nil && testcase.Visibility == domain.TestcaseVisibilitySample, nil
+}
+
+func (u *assignmentUsecase) ExportTestcases(userId string, assignmentId int) (func(w io.Writer) error, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while exporting testcase", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while exporting testcase", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	if len(assignment.Testcases) == 0 {
+		return nil, errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
+	}
+
+	// The archive is in the same layout as the one imported
+	return func(w io.Writer) error {
+		testcaseArchive := zip.NewWriter(w)
+		if err := u.writeTestcases(testcaseArchive, "", assignment.Testcases); err != nil {
+			return err
+		}
+		if err := testcaseArchive.Close(); err != nil {
+			return errs.New(errs.ErrExportTestcase, "cannot close testcase archive", err)
+		}
+		return nil
+	}, nil
+}
+
+func (u *assignmentUsecase) ExportAssignment(userId string, assignmentId int) (func(w io.Writer) error, error) {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get assignment id %d while exporting assignment", assignmentId, err)
+	} else if assignment == nil {
+		return nil, errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, assignment.WorkspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while exporting assignment", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	if len(assignment.Testcases) == 0 {
+		return nil, errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
+	}
+
+	return func(w io.Writer) error {
+		return u.writePackage(assignment, w)
+	}, nil
+}
+
+func (u *assignmentUsecase) ImportAssignment(
+	userId string,
+	workspaceId int,
+	pkg io.ReaderAt,
+	size int64,
+	schedule *domain.AssignmentSchedule,
+) error {
+	files, err := archive.ReadZip(pkg, size, int64(constant.MaxAssignmentPackageSize))
+	if err != nil {
+		return errs.New(errs.ErrInvalidPackage, "cannot read assignment package", err)
+	}
+
+	var manifest domain.AssignmentPackage
+	manifestFile, ok := files[constant.AssignmentPackageManifest]
+	if !ok {
+		return errs.New(errs.ErrInvalidPackage, "assignment package has no %s", constant.AssignmentPackageManifest)
+	}
+	if err := json.Unmarshal(manifestFile, &manifest); err != nil {
+		return errs.New(errs.ErrInvalidPackage, "cannot parse %s", constant.AssignmentPackageManifest, err)
+	}
+	if manifest.Version != constant.AssignmentPackageVersion {
+		return errs.New(errs.ErrInvalidPackage, "unsupported assignment package version %d", manifest.Version)
+	}
+
+	detailFile, ok := files[manifest.DetailFile]
+	if !ok {
+		return errs.New(errs.ErrInvalidPackage, "assignment package has no detail file %s", manifest.DetailFile)
+	}
+	detailMimeType := "text/plain"
+	if path.Ext(manifest.DetailFile) == ".pdf" {
+		detailMimeType = "application/pdf"
+	}
+
+	testcaseFiles := make(map[string][]byte)
+	for filePath, content := range files {
+		if name, ok := strings.CutPrefix(filePath, manifest.TestcaseDirectory+"/"); ok {
+			testcaseFiles[name] = content
+		}
+	}
+	testcases, err := archive.ReadTestcases(testcaseFiles, true)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot read testcases of assignment package", err)
+	}
+
+	var checkerFile *domain.File
+	if manifest.CheckerFile != nil {
+		content, ok := files[*manifest.CheckerFile]
+		if !ok {
+			return errs.New(errs.ErrInvalidPackage, "assignment package has no checker file %s", *manifest.CheckerFile)
+		}
+		checkerFile = &domain.File{Reader: bytes.NewReader(content)}
+	}
+
+	ca := &domain.CreateAssignment{
+		Name:                manifest.Name,
+		Description:         manifest.Description,
+		MemoryLimit:         manifest.MemoryLimit,
+		TimeLimit:           manifest.TimeLimit,
+		Level:               manifest.Level,
+		MaxScore:            manifest.MaxScore,
+		GradingPriority:     &manifest.GradingPriority,
+		PublishDate:         manifest.PublishDate,
+		DueDate:             manifest.DueDate,
+		LatePolicy:          &manifest.LatePolicy,
+		LatePenalty:         &manifest.LatePenalty,
+		LatePenaltyInterval: &manifest.LatePenaltyInterval,
+		LateGracePeriod:     &manifest.LateGracePeriod,
+		MaxAttempt:          manifest.MaxAttempt,
+		SubmissionInterval:  &manifest.SubmissionInterval,
+		CheckerType:         &manifest.CheckerType,
+		CheckerEpsilon:      manifest.CheckerEpsilon,
+		CheckerLanguage:     manifest.CheckerLanguage,
+		CheckerFile:         checkerFile,
+		DetailFile: &domain.File{
+			Reader:   bytes.NewReader(detailFile),
+			MimeType: detailMimeType,
+		},
+		TestcaseFiles: testcases,
+	}
+	if schedule != nil && schedule.PublishDate != nil {
+		ca.PublishDate = *schedule.PublishDate
+	}
+	if schedule != nil && schedule.DueDate != nil {
+		ca.DueDate = schedule.DueDate
+	}
+
+	if err := u.Create(userId, workspaceId, ca); err != nil {
+		return errs.New(errs.SameCode, "cannot create assignment from package", err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) CopyAssignment(
+	userId string,
+	assignmentId int,
+	workspaceId int,
+	schedule *domain.AssignmentSchedule,
+) error {
+	assignment, err := u.Get(assignmentId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get assignment id %d while copying assignment", assignmentId, err)
+	} else if assignment == nil {
+		return errs.New(errs.ErrAssignmentNotFound, "assignment id %d not found", assignmentId)
+	}
+
+	ownerRole := []domain.WorkspaceRole{domain.OwnerRole}
+	for _, id := range []int{assignment.WorkspaceId, workspaceId} {
+		isAuthorized, err := u.workspaceUsecase.CheckPermRole(userId, id, ownerRole)
+		if err != nil {
+			return errs.New(errs.SameCode, "cannot get workspace role while copying assignment", err)
+		}
+		if !isAuthorized {
+			return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+		}
+	}
+
+	if len(assignment.Testcases) == 0 {
+		return errs.New(errs.ErrAssignmentNoTestcase, "invalid assignment id %d", assignmentId)
+	}
+
+	// A copy goes through the package to be the same as exporting and importing
+	var pkg bytes.Buffer
+	if err := u.writePackage(assignment, &pkg); err != nil {
+		return errs.New(errs.SameCode, "cannot package assignment id %d while copying assignment", assignmentId, err)
+	}
+	if err := u.ImportAssignment(
+		userId, workspaceId, bytes.NewReader(pkg.Bytes()), int64(pkg.Len()), schedule,
+	); err != nil {
+		return errs.New(errs.SameCode, "cannot copy assignment id %d to workspace id %d", assignmentId, workspaceId, err)
+	}
+	return nil
+}
+
+// writePackage writes the fields, the detail file, the checker and the latest testcases of an assignment
+func (u *assignmentUsecase) writePackage(assignment *domain.Assignment, w io.Writer) error {
+	pkg := zip.NewWriter(w)
+
+	manifest := domain.AssignmentPackage{
+		Version:             constant.AssignmentPackageVersion,
+		Name:                assignment.Name,
+		Description:         assignment.Description,
+		MemoryLimit:         assignment.MemoryLimit,
+		TimeLimit:           assignment.TimeLimit,
+		Level:               assignment.Level,
+		MaxScore:            assignment.CustomMaxScore,
+		GradingPriority:     assignment.GradingPriority,
+		PublishDate:         assignment.PublishDate,
+		DueDate:             assignment.DueDate,
+		LatePolicy:          assignment.LatePolicy,
+		LatePenalty:         assignment.LatePenalty,
+		LatePenaltyInterval: assignment.LatePenaltyInterval,
+		LateGracePeriod:     assignment.LateGracePeriod,
+		MaxAttempt:          assignment.MaxAttempt,
+		SubmissionInterval:  assignment.SubmissionInterval,
+		CheckerType:         assignment.CheckerType,
+		CheckerEpsilon:      assignment.CheckerEpsilon,
+		CheckerLanguage:     assignment.CheckerLanguage,
+		DetailFile:          "detail/" + path.Base(assignment.DetailUrl),
+		TestcaseDirectory:   "testcases",
+	}
+
+	if err := u.writeArchiveFile(pkg, manifest.DetailFile, assignment.DetailUrl); err != nil {
+		return err
+	}
+	if assignment.CheckerFileUrl != nil {
+		checkerFile := "checker/" + path.Base(*assignment.CheckerFileUrl)
+		if err := u.writeArchiveFile(pkg, checkerFile, *assignment.CheckerFileUrl); err != nil {
+			return err
+		}
+		manifest.CheckerFile = &checkerFile
+	}
+	if err := u.writeTestcases(pkg, manifest.TestcaseDirectory+"/", assignment.Testcases); err != nil {
+		return err
+	}
+
+	file, err := pkg.Create(constant.AssignmentPackageManifest)
+	if err != nil {
+		return errs.New(errs.ErrExportPackage, "cannot create manifest in assignment package", err)
+	}
+	if err := json.NewEncoder(file).Encode(manifest); err != nil {
+		return errs.New(errs.ErrExportPackage, "cannot write manifest in assignment package", err)
+	}
+	if err := pkg.Close(); err != nil {
+		return errs.New(errs.ErrExportPackage, "cannot close assignment package", err)
+	}
+	return nil
+}
+
+// writeTestcases writes testcases as N.in, N.out and their metadata under the directory
+func (u *assignmentUsecase) writeTestcases(archive *zip.Writer, dir string, testcases []domain.Testcase) error {
+	sortedTestcases := make([]domain.Testcase, len(testcases))
+	copy(sortedTestcases, testcases)
+	sort.Slice(sortedTestcases, func(i, j int) bool {
+		return sortedTestcases[i].Id < sortedTestcases[j].Id
+	})
+
+	metadata := make(map[string]domain.TestcaseMetadata, len(sortedTestcases))
+	for i := range sortedTestcases {
+		number := strconv.Itoa(i + 1)
+		if err := u.writeArchiveFile(archive, dir+number+".in", sortedTestcases[i].InputFileUrl); err != nil {
+			return err
+		}
+		if err := u.writeArchiveFile(archive, dir+number+".out", sortedTestcases[i].OutputFileUrl); err != nil {
+			return err
+		}
+		metadata[number] = domain.TestcaseMetadata{
+			Group:      sortedTestcases[i].Group,
+			Weight:     &sortedTestcases[i].Weight,
+			Visibility: &sortedTestcases[i].Visibility,
+		}
+	}
+
+	file, err := archive.Create(dir + constant.TestcaseMetadataFileName)
+	if err != nil {
+		return errs.New(errs.ErrExportTestcase, "cannot create testcase metadata in archive", err)
+	}
+	if err := json.NewEncoder(file).Encode(metadata); err != nil {
+		return errs.New(errs.ErrExportTestcase, "cannot write testcase metadata in archive", err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) writeArchiveFile(archive *zip.Writer, name string, fileUrl string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return errs.New(errs.ErrExportTestcase, "cannot create %s in archive", name, err)
+	}
+	if err := u.seaweedfs.Download(fileUrl, func(content io.Reader) error {
+		_, err := io.Copy(file, content)
+		return err
+	}); err != nil {
+		return errs.New(errs.ErrFileSystem, "cannot download %s to archive", fileUrl, err)
+	}
+	return nil
+}
+
+func (u *assignmentUsecase) CloneWorkspace(
+	userId string,
+	workspaceId int,
+	name *string,
+	startDate time.Time,
+) (*domain.ClonedWorkspace, error) {
+	workspace, err := u.workspaceUsecase.Clone(userId, workspaceId, name)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot clone workspace id %d", workspaceId, err)
+	}
+
+	skippedIds, err := u.cloneAssignments(userId, workspaceId, workspace.Id, startDate)
+	if err != nil {
+		// A partially cloned workspace is deleted to be cloned again from scratch
+		if deleteErr := u.workspaceUsecase.Delete(userId, workspace.Id); deleteErr != nil {
+			return nil, errs.New(errs.SameCode, "cannot delete workspace id %d partially cloned after %v", workspace.Id, err, deleteErr)
+		}
+		return nil, errs.New(errs.SameCode, "cannot clone assignments of workspace id %d", workspaceId, err)
+	}
+
+	return &domain.ClonedWorkspace{
+		RawWorkspace:         *workspace,
+		SkippedAssignmentIds: skippedIds,
+	}, nil
+}
+
+// cloneAssignments copies every assignment of a workspace to another workspace
+// and returns the ids of the assignments skipped for having no testcases
+func (u *assignmentUsecase) cloneAssignments(
+	userId string,
+	workspaceId int,
+	targetWorkspaceId int,
+	startDate time.Time,
+) ([]int, error) {
+	ownerRole := []domain.WorkspaceRole{domain.OwnerRole}
+	for _, id := range []int{workspaceId, targetWorkspaceId} {
+		isAuthorized, err := u.workspaceUsecase.CheckPermRole(userId, id, ownerRole)
+		if err != nil {
+			return nil, errs.New(errs.SameCode, "cannot get workspace role while cloning assignments", err)
+		}
+		if !isAuthorized {
+			return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+		}
+	}
+
+	assignments, err := u.assignmentRepository.List(userId, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListAssignment, "cannot list assignment of workspace id %d", workspaceId, err)
+	}
+	skippedIds := make([]int, 0)
+	if len(assignments) == 0 {
+		return skippedIds, nil
+	}
+
+	// The earliest assignment is published at the start date and the others keep their distance from it
+	firstPublishDate := assignments[0].PublishDate
+	for _, assignment := range assignments {
+		if assignment.PublishDate.Before(firstPublishDate) {
+			firstPublishDate = assignment.PublishDate
+		}
+	}
+	shift := startDate.Sub(firstPublishDate)
+
+	for i := range assignments {
+		assignment := &assignments[i].Assignment
+		// An assignment cannot be created without testcases
+		if len(assignment.Testcases) == 0 {
+			skippedIds = append(skippedIds, assignment.Id)
+			continue
+		}
+
+		publishDate := assignment.PublishDate.Add(shift)
+		schedule := &domain.AssignmentSchedule{PublishDate: &publishDate}
+		if assignment.DueDate != nil {
+			dueDate := assignment.DueDate.Add(shift)
+			schedule.DueDate = &dueDate
+		}
+
+		var pkg bytes.Buffer
+		if err := u.writePackage(assignment, &pkg); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot package assignment id %d while cloning assignments", assignment.Id, err)
+		}
+		if err := u.ImportAssignment(
+			userId, targetWorkspaceId, bytes.NewReader(pkg.Bytes()), int64(pkg.Len()), schedule,
+		); err != nil {
+			return nil, errs.New(errs.SameCode, "cannot clone assignment id %d", assignment.Id, err)
+		}
+	}
+	return skippedIds, nil
+}
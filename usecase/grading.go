@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+	"github.com/codern-org/codern/internal/generator"
+	"github.com/codern-org/codern/platform"
+)
+
+type gradingUsecase struct {
+	rabbitMq          *platform.RabbitMq
+	gradingRepository domain.GradingRepository
+}
+
+func NewGradingUsecase(
+	rabbitMq *platform.RabbitMq,
+	gradingRepository domain.GradingRepository,
+) domain.GradingUsecase {
+	return &gradingUsecase{
+		rabbitMq:          rabbitMq,
+		gradingRepository: gradingRepository,
+	}
+}
+
+func (u *gradingUsecase) CreateDeadLetter(queue string, reason string, body []byte) error {
+	deadLetter := &domain.DeadLetter{
+		Id:     generator.GetId(),
+		Queue:  queue,
+		Reason: reason,
+		Body:   string(body),
+	}
+	if err := u.gradingRepository.CreateDeadLetter(deadLetter); err != nil {
+		return errs.New(errs.ErrCreateDeadLetter, "cannot create dead letter from queue %s", queue, err)
+	}
+	return nil
+}
+
+func (u *gradingUsecase) GetDeadLetter(id int) (*domain.DeadLetter, error) {
+	deadLetter, err := u.gradingRepository.GetDeadLetter(id)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetDeadLetter, "cannot get dead letter id %d", id, err)
+	}
+	return deadLetter, nil
+}
+
+func (u *gradingUsecase) ListDeadLetter() ([]domain.DeadLetter, error) {
+	deadLetters, err := u.gradingRepository.ListDeadLetter()
+	if err != nil {
+		return nil, errs.New(errs.ErrListDeadLetter, "cannot list dead letter", err)
+	}
+	return deadLetters, nil
+}
+
+func (u *gradingUsecase) ReplayDeadLetter(id int) error {
+	deadLetter, err := u.GetDeadLetter(id)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get dead letter id %d while replaying", id, err)
+	} else if deadLetter == nil {
+		return errs.New(errs.ErrDeadLetterNotFound, "dead letter id %d not found", id)
+	}
+
+	// The default exchange routes the message directly back to its original queue
+	if err := u.rabbitMq.Publish("", deadLetter.Queue, 0, []byte(deadLetter.Body)); err != nil {
+		return errs.New(errs.ErrReplayDeadLetter, "cannot publish dead letter id %d", id, err)
+	}
+
+	if err := u.gradingRepository.UpdateDeadLetterReplayed(id); err != nil {
+		return errs.New(errs.ErrReplayDeadLetter, "cannot update replayed dead letter id %d", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"github.com/codern-org/codern/domain"
+	errs "github.com/codern-org/codern/domain/error"
+)
+
+type languageUsecase struct {
+	languageRepository domain.LanguageRepository
+	workspaceUsecase   domain.WorkspaceUsecase
+}
+
+func NewLanguageUsecase(
+	languageRepository domain.LanguageRepository,
+	workspaceUsecase domain.WorkspaceUsecase,
+) domain.LanguageUsecase {
+	return &languageUsecase{
+		languageRepository: languageRepository,
+		workspaceUsecase:   workspaceUsecase,
+	}
+}
+
+func (u *languageUsecase) Get(id string, workspaceId int) (*domain.Language, error) {
+	language, err := u.languageRepository.Get(id, workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrGetLanguage, "cannot get language id %s", id, err)
+	}
+	return language, nil
+}
+
+func (u *languageUsecase) List() ([]domain.Language, error) {
+	languages, err := u.languageRepository.List()
+	if err != nil {
+		return nil, errs.New(errs.ErrListLanguage, "cannot list language", err)
+	}
+	return languages, nil
+}
+
+func (u *languageUsecase) ListByWorkspace(workspaceId int) ([]domain.Language, error) {
+	languages, err := u.languageRepository.ListByWorkspace(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListLanguage, "cannot list language of workspace id %d", workspaceId, err)
+	}
+	return languages, nil
+}
+
+func (u *languageUsecase) UpdateWorkspaceLanguage(
+	userId string,
+	workspaceId int,
+	languageId string,
+	isEnabled bool,
+) error {
+	isAuthorized, err := u.workspaceUsecase.CheckPerm(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while updating workspace language", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	language, err := u.Get(languageId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get language id %s while updating workspace language", languageId, err)
+	} else if language == nil {
+		return errs.New(errs.ErrLanguageNotFound, "language id %s not found", languageId)
+	}
+
+	if err := u.languageRepository.UpdateWorkspaceLanguage(workspaceId, languageId, isEnabled); err != nil {
+		return errs.New(errs.ErrUpdateLanguage, "cannot update language id %s of workspace id %d", languageId, workspaceId, err)
+	}
+	return nil
+}
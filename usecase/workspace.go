@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/codern-org/codern/domain"
@@ -289,6 +291,38 @@ func (u *workspaceUsecase) ListParticipant(workspaceId int) ([]domain.WorkspaceP
 	return participants, nil
 }
 
+func (u *workspaceUsecase) ListLevel(workspaceId int) ([]domain.WorkspaceLevel, error) {
+	customLevels, err := u.workspaceRepository.ListLevel(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.ErrListWorkspaceLevel, "cannot list level of workspace id %d", workspaceId, err)
+	}
+
+	customLevelByName := make(map[domain.AssignmentLevel]domain.WorkspaceLevel)
+	for _, level := range customLevels {
+		customLevelByName[level.Level] = level
+	}
+
+	levels := make([]domain.WorkspaceLevel, 0, len(domain.DefaultAssignmentLevels)+len(customLevels))
+	for _, level := range domain.DefaultAssignmentLevels {
+		if customLevel, ok := customLevelByName[level]; ok {
+			levels = append(levels, customLevel)
+			continue
+		}
+		score, _ := domain.GetDefaultLevelScore(level)
+		levels = append(levels, domain.WorkspaceLevel{
+			WorkspaceId: workspaceId,
+			Level:       level,
+			Score:       score,
+		})
+	}
+	for _, level := range customLevels {
+		if _, ok := domain.GetDefaultLevelScore(level.Level); !ok {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
 func (u *workspaceUsecase) Update(userId string, workspaceId int, uw *domain.UpdateWorkspace) error {
 	isAuthorized, err := u.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole, domain.AdminRole})
 	if err != nil {
@@ -394,6 +428,111 @@ func (u *workspaceUsecase) UpdateParticipant(
 	return nil
 }
 
+func (u *workspaceUsecase) UpdateLevel(
+	userId string,
+	workspaceId int,
+	level domain.AssignmentLevel,
+	score float64,
+) error {
+	isAuthorized, err := u.CheckPerm(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while updating workspace level", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	if err := u.workspaceRepository.UpdateLevel(&domain.WorkspaceLevel{
+		WorkspaceId: workspaceId,
+		Level:       level,
+		Score:       score,
+	}); err != nil {
+		return errs.New(errs.ErrUpdateWorkspaceLevel, "cannot update level %s of workspace id %d", level, workspaceId, err)
+	}
+	return nil
+}
+
+func (u *workspaceUsecase) Clone(userId string, workspaceId int, name *string) (*domain.RawWorkspace, error) {
+	isAuthorized, err := u.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole})
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace role while cloning workspace", err)
+	}
+	if !isAuthorized {
+		return nil, errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	source, err := u.GetRaw(workspaceId)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot get workspace id %d while cloning workspace", workspaceId, err)
+	} else if source == nil {
+		return nil, errs.New(errs.ErrWorkspaceNotFound, "workspace id %d not found", workspaceId)
+	}
+
+	cw := &domain.CreateWorkspace{Name: source.Name}
+	if name != nil {
+		cw.Name = *name
+	}
+	if source.ProfileUrl != constant.DefaultProfileUrl {
+		var profile bytes.Buffer
+		if err := u.seaweedfs.Download(source.ProfileUrl, func(content io.Reader) error {
+			_, err := io.Copy(&profile, content)
+			return err
+		}); err != nil {
+			return nil, errs.New(errs.ErrFileSystem, "cannot download profile of workspace id %d", workspaceId, err)
+		}
+		cw.Profile = &profile
+	}
+
+	workspace, err := u.Create(userId, cw)
+	if err != nil {
+		return nil, errs.New(errs.SameCode, "cannot create workspace while cloning workspace id %d", workspaceId, err)
+	}
+
+	// A partially cloned workspace is deleted to be cloned again from scratch
+	if err := u.cloneMembers(workspaceId, workspace.Id, userId); err != nil {
+		if deleteErr := u.workspaceRepository.Delete(workspace.Id); deleteErr != nil {
+			return nil, errs.New(errs.ErrDeleteWorkspace, "cannot delete workspace id %d partially cloned after %v", workspace.Id, err, deleteErr)
+		}
+		return nil, errs.New(errs.SameCode, "cannot clone workspace id %d", workspaceId, err)
+	}
+
+	return workspace, nil
+}
+
+// cloneMembers copies the admins, the owners and the levels of a workspace to another workspace
+func (u *workspaceUsecase) cloneMembers(workspaceId int, targetWorkspaceId int, userId string) error {
+	// Members join the new workspace by themselves, the cloner is already the owner
+	participants, err := u.workspaceRepository.ListParticipant(workspaceId)
+	if err != nil {
+		return errs.New(errs.ErrListWorkspaceParticipant, "cannot list participant of workspace id %d", workspaceId, err)
+	}
+	for _, participant := range participants {
+		if participant.UserId == userId || participant.Role == domain.MemberRole {
+			continue
+		}
+		if err := u.workspaceRepository.CreateParticipant(&domain.WorkspaceParticipant{
+			WorkspaceId: targetWorkspaceId,
+			UserId:      participant.UserId,
+			Role:        participant.Role,
+			Favorite:    false,
+		}); err != nil {
+			return errs.New(errs.ErrCreateWorkspaceParticipant, "cannot clone participant %s", participant.UserId, err)
+		}
+	}
+
+	levels, err := u.workspaceRepository.ListLevel(workspaceId)
+	if err != nil {
+		return errs.New(errs.ErrListWorkspaceLevel, "cannot list level of workspace id %d", workspaceId, err)
+	}
+	for _, level := range levels {
+		level.WorkspaceId = targetWorkspaceId
+		if err := u.workspaceRepository.UpdateLevel(&level); err != nil {
+			return errs.New(errs.ErrUpdateWorkspaceLevel, "cannot clone level %s", level.Level, err)
+		}
+	}
+	return nil
+}
+
 func (u *workspaceUsecase) Delete(userId string, workspaceId int) error {
 	isAuthorized, err := u.CheckPermRole(userId, workspaceId, []domain.WorkspaceRole{domain.OwnerRole})
 	if err != nil {
@@ -460,3 +599,21 @@ func (u *workspaceUsecase) DeleteParticipant(workspaceId int, removerUserId stri
 	}
 	return nil
 }
+
+func (u *workspaceUsecase) DeleteLevel(userId string, workspaceId int, level domain.AssignmentLevel) error {
+	isAuthorized, err := u.CheckPerm(userId, workspaceId)
+	if err != nil {
+		return errs.New(errs.SameCode, "cannot get workspace role while deleting workspace level", err)
+	}
+	if !isAuthorized {
+		return errs.New(errs.ErrWorkspaceNoPerm, "permission denied")
+	}
+
+	isDeleted, err := u.workspaceRepository.DeleteLevel(workspaceId, level)
+	if err != nil {
+		return errs.New(errs.ErrDeleteWorkspaceLevel, "cannot delete level %s of workspace id %d", level, workspaceId, err)
+	} else if !isDeleted {
+		return errs.New(errs.ErrLevelInUse, "level %s of workspace id %d is used by assignments", level, workspaceId)
+	}
+	return nil
+}